@@ -0,0 +1,293 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"time"
+
+	"foxygo.at/dreg/pb"
+	"foxygo.at/dreg/registry"
+	"github.com/dustin/go-humanize"
+	"google.golang.org/grpc"
+)
+
+// maxCachedBlobSize is the size of the largest blob stored in the cache.
+// It is large enough for image configs but not most layers.
+const maxCachedBlobSize = 1 << 20
+
+var cacheDigestRE = regexp.MustCompile(`^sha256:[a-f0-9]{64}$`)
+
+type cache struct {
+	Info  cacheInfo  `cmd:"" help:"Show location and size of cache"`
+	Prune cachePrune `cmd:"" help:"Remove manifests and blobs from cache"`
+}
+
+type cacheInfo struct{}
+
+type cachePrune struct {
+	OlderThan string `help:"Only remove entries not used for longer than this, such as 30d or 12h"`
+}
+
+// diskCache is a content-addressable cache of manifests and blobs, stored
+// in files named by registry host and digest. Only sha256 digests are
+// cached, and entries are verified against their digest when read.
+type diskCache struct {
+	dir string
+}
+
+// defaultCacheDir returns the dreg directory in the user cache directory,
+// $XDG_CACHE_HOME or ~/.cache on Linux.
+func defaultCacheDir() (string, error) {
+	dir, err := os.UserCacheDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "dreg"), nil
+}
+
+func (c *diskCache) path(host, digest string) (string, bool) {
+	if !cacheDigestRE.MatchString(digest) || host == "" || host == ".." || strings.ContainsAny(host, `/\`) {
+		return "", false
+	}
+	alg, hex := registry.SplitDigest(digest)
+	return filepath.Join(c.dir, host, alg, hex), true
+}
+
+// get returns the cached contents for digest from the registry host. A hit
+// updates the modification time of the entry for cache prune --older-than.
+func (c *diskCache) get(host, digest string) ([]byte, bool) {
+	path, ok := c.path(host, digest)
+	if !ok {
+		return nil, false
+	}
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return nil, false
+	}
+	if registry.VerifyDigest(digest, b) != nil {
+		_ = os.Remove(path)
+		return nil, false
+	}
+	now := time.Now()
+	_ = os.Chtimes(path, now, now)
+	return b, true
+}
+
+// put stores b as the contents for digest from the registry host. The file
+// is written atomically so concurrent readers never see a partial entry.
+func (c *diskCache) put(host, digest string, b []byte) error {
+	path, ok := c.path(host, digest)
+	if !ok {
+		return nil
+	}
+	if err := registry.VerifyDigest(digest, b); err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return err
+	}
+	f, err := os.CreateTemp(filepath.Dir(path), ".tmp-*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(f.Name())
+	if _, err := f.Write(b); err != nil {
+		f.Close()
+		return err
+	}
+	if err := f.Close(); err != nil {
+		return err
+	}
+	return os.Rename(f.Name(), path)
+}
+
+// walk calls fn for each entry in the cache.
+func (c *diskCache) walk(fn func(path string, info fs.FileInfo) error) error {
+	err := filepath.WalkDir(c.dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() || strings.HasPrefix(d.Name(), ".tmp-") {
+			return nil
+		}
+		info, err := d.Info()
+		if err != nil {
+			return err
+		}
+		return fn(path, info)
+	})
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil
+	}
+	return err
+}
+
+func (ci *cacheInfo) Run(cfg *config) error {
+	dc, err := cfg.diskCache()
+	if err != nil {
+		return err
+	}
+	n, size := 0, uint64(0)
+	err = dc.walk(func(_ string, info fs.FileInfo) error {
+		n++
+		size += uint64(info.Size())
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	if out := cfg.records(); out != nil {
+		return writeRecords(out, &pb.CacheRecord{Location: dc.dir, Entries: int64(n), Size: size})
+	}
+	fmt.Printf("Location: %s\nEntries:  %d\nSize:     %s\n", dc.dir, n, humanize.Bytes(size))
+	return nil
+}
+
+func (p *cachePrune) Run(cfg *config) error {
+	dc, err := cfg.diskCache()
+	if err != nil {
+		return err
+	}
+	var cutoff time.Time
+	if p.OlderThan != "" {
+		age, err := parseAge(p.OlderThan)
+		if err != nil {
+			return fmt.Errorf("--older-than: %w", err)
+		}
+		cutoff = time.Now().Add(-age)
+	}
+	n, size := 0, uint64(0)
+	err = dc.walk(func(path string, info fs.FileInfo) error {
+		if !cutoff.IsZero() && info.ModTime().After(cutoff) {
+			return nil
+		}
+		if err := os.Remove(path); err != nil {
+			return err
+		}
+		n++
+		size += uint64(info.Size())
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	if out := cfg.records(); out != nil {
+		return writeRecords(out, &pb.CacheRecord{Location: dc.dir, Entries: int64(n), Size: size})
+	}
+	fmt.Printf("Removed %d entries, %s\n", n, humanize.Bytes(size))
+	return nil
+}
+
+// cachedClient is a RegistryClient that reads manifests and small blobs
+// from a diskCache if they are there, and stores them there if not.
+// Manifests requested by tag are resolved to a digest with GetDigest first.
+type cachedClient struct {
+	pb.RegistryClient
+	cache *diskCache
+	// host is the registry host.
+	host string
+}
+
+func (c *cachedClient) GetManifest(ctx context.Context, req *pb.GetManifestRequest, opts ...grpc.CallOption) (*pb.GetManifestResponse, error) {
+	digest := req.Reference
+	if !cacheDigestRE.MatchString(digest) {
+		resp, err := c.GetDigest(ctx, &pb.GetDigestRequest{Name: req.Name, Reference: req.Reference}, opts...)
+		if err != nil {
+			return nil, err
+		}
+		digest = resp.Digest
+	}
+	if raw, ok := c.cache.get(c.host, digest); ok {
+		return cachedManifest(digest, raw), nil
+	}
+
+	if digest != "" {
+		req = &pb.GetManifestRequest{Name: req.Name, Reference: digest}
+	}
+	resp, err := c.RegistryClient.GetManifest(ctx, req, opts...)
+	if err != nil {
+		return nil, err
+	}
+	if digest == "" {
+		digest = resp.Digest
+	}
+	// Caching is best effort.
+	_ = c.cache.put(c.host, digest, resp.Raw)
+	return resp, nil
+}
+
+// cachedManifest returns a GetManifest response for a manifest read from
+// the cache, taking the media type from the manifest itself.
+func cachedManifest(digest string, raw []byte) *pb.GetManifestResponse {
+	resp := &pb.GetManifestResponse{Digest: digest, Raw: raw}
+	if m, err := registry.ParseManifest("", raw); err == nil {
+		resp.MediaType = registry.ManifestMediaType(m)
+	}
+	return resp
+}
+
+func (c *cachedClient) GetBlob(ctx context.Context, req *pb.GetBlobRequest, opts ...grpc.CallOption) (pb.Registry_GetBlobClient, error) {
+	if req.Offset != 0 {
+		return c.RegistryClient.GetBlob(ctx, req, opts...)
+	}
+	if b, ok := c.cache.get(c.host, req.Digest); ok {
+		return &cachedBlobStream{data: b}, nil
+	}
+	stream, err := c.RegistryClient.GetBlob(ctx, req, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return &cachingBlobStream{Registry_GetBlobClient: stream, client: c, digest: req.Digest}, nil
+}
+
+// cachedBlobStream is a GetBlob stream of a blob read from the cache. Only
+// Recv is implemented.
+type cachedBlobStream struct {
+	grpc.ClientStream
+	data []byte
+}
+
+func (s *cachedBlobStream) Recv() (*pb.GetBlobResponse, error) {
+	if s.data == nil {
+		return nil, io.EOF
+	}
+	resp := &pb.GetBlobResponse{Data: s.data}
+	s.data = nil
+	return resp, nil
+}
+
+// cachingBlobStream is a GetBlob stream that stores the blob in the cache
+// when it has been received in full, unless it is larger than
+// maxCachedBlobSize.
+type cachingBlobStream struct {
+	pb.Registry_GetBlobClient
+	client   *cachedClient
+	digest   string
+	buf      []byte
+	tooLarge bool
+}
+
+func (s *cachingBlobStream) Recv() (*pb.GetBlobResponse, error) {
+	resp, err := s.Registry_GetBlobClient.Recv()
+	if errors.Is(err, io.EOF) && !s.tooLarge {
+		_ = s.client.cache.put(s.client.host, s.digest, s.buf)
+	}
+	if err != nil {
+		return nil, err
+	}
+	if !s.tooLarge {
+		if len(s.buf)+len(resp.Data) > maxCachedBlobSize {
+			s.tooLarge, s.buf = true, nil
+		} else {
+			s.buf = append(s.buf, resp.Data...)
+		}
+	}
+	return resp, nil
+}
@@ -0,0 +1,104 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"io"
+
+	"foxygo.at/dreg/pb"
+	"foxygo.at/dreg/registry"
+)
+
+type cp struct {
+	Src       string `arg:"" help:"Image to copy, as [registry/]name[:tag] or [registry/]name@digest"`
+	Dst       string `arg:"" help:"Image to copy to, as [registry/]name[:tag]"`
+	ChunkSize int    `default:"16777216" help:"Size of blob upload chunks in bytes (0 to upload blobs in one request)"`
+}
+
+// cp.Run executes the cp cli subcommand, copying an image with its blobs,
+// and for an image index, its child manifests, to another repository or
+// registry. Manifests are copied byte for byte so digests are preserved.
+func (c *cp) Run(cfg *config) error {
+	ctx := cfg.context()
+	srcClient, src, err := cfg.resolveImage(c.Src)
+	if err != nil {
+		return err
+	}
+	dstClient, dst, err := cfg.resolveImage(c.Dst)
+	if err != nil {
+		return err
+	}
+
+	pusher := &imagePusher{
+		client:    dstClient,
+		src:       &registrySource{client: srcClient, name: src.Path},
+		name:      dst.Path,
+		chunkSize: c.ChunkSize,
+	}
+	if cfg.registryURL(src) == cfg.registryURL(dst) && src.Path != dst.Path {
+		pusher.from = src.Path
+	}
+	resp, digest, err := copyImage(ctx, pusher, src.TagOrDigest(), dst.TagOrDigest())
+	if err != nil {
+		return err
+	}
+	if out := cfg.records(); out != nil {
+		return writeRecords(out, &pb.ImageRecord{Repository: dst.Name(), Tag: dst.Tag, Digest: digest, MediaType: registry.ManifestMediaType(resp.Manifest)})
+	}
+	fmt.Printf("%s@%s\n", dst.Name(), digest)
+	return nil
+}
+
+// copyImage pushes the image that reference, a tag or digest, refers to in
+// the registrySource of ip with dstReference. It returns the source
+// manifest and the digest of the image.
+func copyImage(ctx context.Context, ip *imagePusher, reference, dstReference string) (*pb.GetManifestResponse, string, error) {
+	src := ip.src.(*registrySource)
+	resp, err := src.client.Manifest(ctx, src.name, reference)
+	if err != nil {
+		return nil, "", err
+	}
+	desc := &pb.Descriptor{MediaType: resp.MediaType, Size: uint64(len(resp.Raw)), Digest: resp.Digest}
+	digest, err := ip.pushRawManifest(ctx, desc, resp.Raw, dstReference)
+	return resp, digest, err
+}
+
+// registrySource is a blobSource for a repository in a registry.
+type registrySource struct {
+	client *registry.Client
+	name   string
+}
+
+func (s *registrySource) readManifest(ctx context.Context, digest string) ([]byte, error) {
+	req := &pb.GetManifestRequest{Name: s.name, Reference: digest}
+	resp, err := s.client.GetManifest(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+	if err := registry.VerifyDigest(digest, resp.Raw); err != nil {
+		return nil, fmt.Errorf("%s@%s: %w", s.name, digest, err)
+	}
+	return resp.Raw, nil
+}
+
+func (s *registrySource) openBlob(ctx context.Context, digest string) (io.ReadCloser, error) {
+	ctx, cancel := context.WithCancel(ctx)
+	r, err := s.client.OpenBlob(ctx, s.name, digest, 0)
+	if err != nil {
+		cancel()
+		return nil, err
+	}
+	return &cancelReader{Reader: r, cancel: cancel}, nil
+}
+
+// cancelReader is an io.ReadCloser that cancels the context of the request
+// it is reading from when closed.
+type cancelReader struct {
+	io.Reader
+	cancel context.CancelFunc
+}
+
+func (r *cancelReader) Close() error {
+	r.cancel()
+	return nil
+}
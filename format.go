@@ -0,0 +1,153 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"text/template"
+	"text/template/parse"
+	"time"
+
+	"foxygo.at/dreg/pb"
+	"github.com/dustin/go-humanize"
+)
+
+// imageData is the data for an image in a list --format template. Size,
+// Platform and Created are only fetched if the template refers to them.
+type imageData struct {
+	Repository string
+	Tag        string
+	Digest     string
+	MediaType  string
+	Platform   string
+	// Size is the sum of the compressed sizes of the image layers.
+	Size    uint64
+	Created time.Time
+}
+
+// listFormat is a --format template for list output and the fields it
+// refers to.
+type listFormat struct {
+	tmpl   *template.Template
+	fields map[string]bool
+}
+
+var formatFuncs = template.FuncMap{
+	"humanize":    humanize.Bytes,
+	"ago":         ago,
+	"shortDigest": shortDigest,
+	"json":        jsonString,
+}
+
+func parseListFormat(format string) (*listFormat, error) {
+	tmpl, err := template.New("format").Funcs(formatFuncs).Parse(format + "\n")
+	if err != nil {
+		return nil, fmt.Errorf("invalid format: %w", err)
+	}
+	f := &listFormat{tmpl: tmpl, fields: map[string]bool{}}
+	for _, t := range tmpl.Templates() {
+		if t.Tree != nil {
+			f.walk(t.Tree.Root)
+		}
+	}
+	return f, nil
+}
+
+// uses reports whether the template refers to any of the named fields. A
+// nil listFormat refers to no fields.
+func (f *listFormat) uses(fields ...string) bool {
+	if f == nil {
+		return false
+	}
+	for _, field := range fields {
+		if f.fields[field] || f.fields["."] {
+			return true
+		}
+	}
+	return false
+}
+
+func (f *listFormat) execute(w io.Writer, r *pb.ImageRecord) error {
+	data := &imageData{
+		Repository: r.Repository,
+		Tag:        r.Tag,
+		Digest:     r.Digest,
+		MediaType:  r.MediaType,
+		Platform:   r.Platform,
+		Size:       r.Size,
+	}
+	if r.Created != nil {
+		data.Created = r.Created.AsTime()
+	}
+	return f.tmpl.Execute(w, data)
+}
+
+// walk records the fields of the template data that node refers to. A
+// reference to the data itself, as in {{json .}}, is recorded as ".".
+func (f *listFormat) walk(node parse.Node) {
+	switch n := node.(type) {
+	case *parse.ListNode:
+		if n == nil {
+			return
+		}
+		for _, c := range n.Nodes {
+			f.walk(c)
+		}
+	case *parse.ActionNode:
+		f.walk(n.Pipe)
+	case *parse.PipeNode:
+		if n == nil {
+			return
+		}
+		for _, c := range n.Cmds {
+			f.walk(c)
+		}
+	case *parse.CommandNode:
+		for _, c := range n.Args {
+			f.walk(c)
+		}
+	case *parse.ChainNode:
+		f.walk(n.Node)
+	case *parse.IfNode:
+		f.walkBranch(&n.BranchNode)
+	case *parse.RangeNode:
+		f.walkBranch(&n.BranchNode)
+	case *parse.WithNode:
+		f.walkBranch(&n.BranchNode)
+	case *parse.TemplateNode:
+		f.walk(n.Pipe)
+	case *parse.FieldNode:
+		f.fields[n.Ident[0]] = true
+	case *parse.VariableNode:
+		// $ is the template data; other variables are not followed.
+		if n.Ident[0] == "$" {
+			if len(n.Ident) > 1 {
+				f.fields[n.Ident[1]] = true
+			} else {
+				f.fields["."] = true
+			}
+		}
+	case *parse.DotNode:
+		f.fields["."] = true
+	}
+}
+
+func (f *listFormat) walkBranch(n *parse.BranchNode) {
+	f.walk(n.Pipe)
+	f.walk(n.List)
+	f.walk(n.ElseList)
+}
+
+// ago returns how long ago t was in words, or an empty string for the
+// zero time.
+func ago(t time.Time) string {
+	if t.IsZero() {
+		return ""
+	}
+	return humanize.Time(t)
+}
+
+func jsonString(v interface{}) (string, error) {
+	b, err := json.Marshal(v)
+	return string(b), err
+}
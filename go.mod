@@ -3,10 +3,10 @@ module foxygo.at/dreg
 go 1.16
 
 require (
-	foxygo.at/protog v0.0.9
 	github.com/alecthomas/kong v0.2.17
 	github.com/dustin/go-humanize v1.0.0
 	google.golang.org/genproto v0.0.0-20210824181836-a4879c3d0e89
 	google.golang.org/grpc v1.40.0
 	google.golang.org/protobuf v1.27.1
+	gopkg.in/yaml.v3 v3.0.1
 )
@@ -0,0 +1,213 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+	"text/tabwriter"
+
+	"foxygo.at/dreg/pb"
+	"foxygo.at/dreg/reference"
+	"foxygo.at/dreg/registry"
+	"github.com/dustin/go-humanize"
+	"google.golang.org/protobuf/types/known/emptypb"
+)
+
+type inspect struct {
+	Images   []string `arg:"" name:"image" help:"Images to inspect, as [registry/]name[:tag] or [registry/]name@digest"`
+	Platform string   `short:"p" help:"Only inspect platform os/arch[/variant] of multi-platform images"`
+	NoTrunc  bool     `help:"Do not truncate history"`
+}
+
+// inspectedImage is the manifest and config of an image for a single
+// platform. It is output as is with --output json.
+type inspectedImage struct {
+	Image     string          `json:"image"`
+	Digest    string          `json:"digest"`
+	MediaType string          `json:"mediaType"`
+	Platform  string          `json:"platform"`
+	Manifest  json.RawMessage `json:"manifest"`
+	Config    json.RawMessage `json:"config"`
+
+	manifest *pb.ImageManifest
+	config   *pb.ImageConfig
+}
+
+// Maximum width of the CREATED BY history column unless --no-trunc is set.
+const historyTruncWidth = 60
+
+// inspect.Run executes the inspect cli subcommand, showing the manifest,
+// config and history of images.
+func (i *inspect) Run(cfg *config) error {
+	ctx := cfg.context()
+	var filter *pb.Platform
+	if i.Platform != "" {
+		var err error
+		if filter, err = registry.ParsePlatform(i.Platform); err != nil {
+			return err
+		}
+	}
+
+	var result []*inspectedImage
+	for _, image := range i.Images {
+		client, ref, err := cfg.resolveImage(image)
+		if err != nil {
+			return err
+		}
+		images, err := inspectImage(ctx, client, image, ref, filter)
+		if err != nil {
+			return err
+		}
+		result = append(result, images...)
+	}
+
+	if out := cfg.records(); out != nil {
+		for _, ii := range result {
+			b, err := json.Marshal(ii)
+			if err != nil {
+				return err
+			}
+			if err := out.writeJSON(b); err != nil {
+				return err
+			}
+		}
+		return out.close()
+	}
+	for n, ii := range result {
+		if n > 0 {
+			fmt.Println()
+		}
+		i.print(os.Stdout, ii)
+	}
+	return nil
+}
+
+// inspectImage fetches the manifest and config of image, or for an image
+// index, the manifests and configs of each of its platforms matching filter.
+func inspectImage(ctx context.Context, client *registry.Client, image string, ref reference.Reference, filter *pb.Platform) ([]*inspectedImage, error) {
+	name := ref.Path
+	resp, err := client.Manifest(ctx, name, ref.TagOrDigest())
+	if err != nil {
+		return nil, err
+	}
+	images, err := client.Images(ctx, name, resp, filter)
+	if err != nil {
+		return nil, err
+	}
+	var result []*inspectedImage
+	for _, pi := range images {
+		config, raw, err := client.ImageConfig(ctx, name, pi.Image)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", image, err)
+		}
+		platform := pi.Platform
+		if platform == nil {
+			platform = &pb.Platform{Os: config.Os, Architecture: config.Architecture, Variant: config.Variant}
+		}
+		ii := &inspectedImage{
+			Image:     image,
+			Digest:    pi.Digest,
+			MediaType: pi.MediaType,
+			Platform:  registry.FormatPlatform(platform),
+			Manifest:  pi.Raw,
+			Config:    raw,
+			manifest:  pi.Image,
+			config:    config,
+		}
+		result = append(result, ii)
+	}
+	return result, nil
+}
+
+func (i *inspect) print(out io.Writer, ii *inspectedImage) {
+	w := tabwriter.NewWriter(out, 0, 0, 1, ' ', 0)
+	field := func(key, val string) {
+		if val != "" {
+			fmt.Fprintf(w, "%s:\t%s\n", key, val)
+		}
+	}
+	list := func(key string, vals []string) {
+		for n, val := range vals {
+			if n > 0 {
+				key = ""
+			}
+			fmt.Fprintf(w, "%s\t%s\n", key+":", val)
+		}
+	}
+	c := ii.config
+	cc := c.GetConfig()
+	field("Image", ii.Image)
+	field("Digest", ii.Digest)
+	field("Media type", ii.MediaType)
+	field("Platform", ii.Platform)
+	if c.Created != nil {
+		created := c.Created.AsTime()
+		field("Created", created.Format("2006-01-02 15:04:05 MST")+" ("+humanize.Time(created)+")")
+	}
+	field("Author", c.Author)
+	field("Entrypoint", jsonList(cc.GetEntrypoint()))
+	field("Cmd", jsonList(cc.GetCmd()))
+	field("Working dir", cc.GetWorkingDir())
+	field("User", cc.GetUser())
+	list("Env", cc.GetEnv())
+	list("Labels", sortedKeyValues(cc.GetLabels()))
+	list("Exposed ports", sortedKeys(cc.GetExposedPorts()))
+	list("Volumes", sortedKeys(cc.GetVolumes()))
+	field("Size", humanize.Bytes(registry.LayerSize(ii.manifest))+" (compressed)")
+	w.Flush()
+
+	if len(c.History) == 0 {
+		return
+	}
+	fmt.Fprintln(out, "\nHistory:")
+	w = tabwriter.NewWriter(out, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "CREATED\tCREATED BY\tSIZE")
+	layers := ii.manifest.Layers
+	for _, h := range c.History {
+		size := "0 B"
+		if !h.EmptyLayer && len(layers) > 0 {
+			size = humanize.Bytes(layers[0].Size)
+			layers = layers[1:]
+		}
+		created := ""
+		if h.Created != nil {
+			created = humanize.Time(h.Created.AsTime())
+		}
+		createdBy := strings.Join(strings.Fields(h.CreatedBy), " ")
+		if r := []rune(createdBy); !i.NoTrunc && len(r) > historyTruncWidth {
+			createdBy = string(r[:historyTruncWidth-3]) + "..."
+		}
+		fmt.Fprintf(w, "%s\t%s\t%s\n", created, createdBy, size)
+	}
+	w.Flush()
+}
+
+func jsonList(vals []string) string {
+	if len(vals) == 0 {
+		return ""
+	}
+	b, _ := json.Marshal(vals)
+	return string(b)
+}
+
+func sortedKeyValues(m map[string]string) []string {
+	result := make([]string, 0, len(m))
+	for k, v := range m {
+		result = append(result, k+"="+v)
+	}
+	sort.Strings(result)
+	return result
+}
+
+func sortedKeys(m map[string]*emptypb.Empty) []string {
+	result := make([]string, 0, len(m))
+	for k := range m {
+		result = append(result, k)
+	}
+	sort.Strings(result)
+	return result
+}
@@ -3,65 +3,111 @@ package main
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"net"
 	"net/url"
 	"os"
+	"os/signal"
 	"sort"
 	"strings"
+	"syscall"
 	"text/tabwriter"
+	"time"
 
 	"foxygo.at/dreg/pb"
-	"foxygo.at/protog/httprule"
+	"foxygo.at/dreg/reference"
+	"foxygo.at/dreg/registry"
 	"github.com/alecthomas/kong"
 	"github.com/dustin/go-humanize"
 	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
 )
 
 type config struct {
-	Check check `cmd:"" help:"Check that registry supports V2 API"`
-	List  list  `cmd:"" help:"List images in registry"`
-	Rm    rm    `cmd:"" aliases:"rmi" help:"Remove images from registry"`
-	Repos repos `cmd:"" help:"List repositories in registry"`
+	Check   check   `cmd:"" help:"Check that registry supports V2 API"`
+	List    list    `cmd:"" help:"List images in registry"`
+	Rm      rm      `cmd:"" aliases:"rmi" help:"Remove images from registry"`
+	Inspect inspect `cmd:"" help:"Show details of images in registry"`
+	Repos   repos   `cmd:"" help:"List repositories in registry"`
+	Push    push    `cmd:"" help:"Push image from OCI image layout or docker save tarball to registry"`
+	Prune   prune   `cmd:"" help:"Delete old images from registry according to retention rules"`
+	Cp      cp      `cmd:"" help:"Copy image between repositories or registries"`
+	Tag     tag     `cmd:"" help:"Add tags to image in registry"`
+	Cache   cache   `cmd:"" help:"Manage local cache of manifests and image configs"`
+	Serve   serve   `cmd:"" help:"Run a registry server"`
+	Proxy   proxy   `cmd:"" help:"Run a pull-through caching registry proxy"`
+	Sync    syncCmd `cmd:"" help:"Copy images between registries, skipping images already copied"`
+	Save    save    `cmd:"" help:"Save images from registry to docker save tarball or OCI image layout"`
 
-	DockerConfig string `type:"path" default:"~/.docker/config.json" help:"Path to docker config file for auth creds"`
-	URL          string `default:"http://localhost:5000" env:"REGISTRY" help:"URL of registry"`
-	Verbose      bool   `short:"v" help:"Verbose output"`
+	DockerConfig string        `type:"path" default:"~/.docker/config.json" help:"Path to docker config file for auth creds"`
+	URL          string        `default:"http://localhost:5000" env:"REGISTRY" help:"URL of registry"`
+	Verbose      bool          `short:"v" help:"Verbose output"`
+	NoCache      bool          `help:"Do not use local cache of manifests and image configs"`
+	Retries      int           `default:"3" help:"Number of times to retry requests that fail with a transient error"`
+	Timeout      time.Duration `help:"Timeout for each request, such as 30s (0 for no timeout)"`
+	Deadline     time.Duration `help:"Time limit for the whole command, such as 10m (0 for no limit)"`
+	Output       string        `enum:"text,json,jsonl,yaml,csv" default:"text" help:"Output format: text, json, jsonl, yaml or csv"`
 
-	client pb.RegistryClient
-	dcfg   dockerConfig
+	// ctx is cancelled on SIGINT or SIGTERM or when --deadline is
+	// exceeded.
+	ctx    context.Context
+	client *registry.Client
+	dcfg   registry.DockerConfig
+	// clients are the clients for registries other than --url, by URL.
+	clients map[string]*registry.Client
 }
 
 type check struct{}
 
-type repos struct{}
+type repos struct {
+	PageSize int32 `help:"Number of repositories to request per page (0 for registry default)"`
+	Limit    int   `help:"Maximum number of repositories to list (0 for no limit)"`
+}
 
 type list struct {
-	Repositories []string `arg:"" optional:"" name:"repository" help:"Repositories to list"`
+	Repositories []string `arg:"" optional:"" name:"repository" help:"Repositories to list, optionally prefixed with registry host"`
 	Sizes        bool     `short:"s" help:"Show image sizes (slow)"`
+	Platform     string   `short:"p" help:"Only list images for platform os/arch[/variant] (slow)"`
 	Table        bool     `help:"Show output as a table"`
-}
-
-type rm struct {
-	Images []string `arg:"" name:"image" help:"Images to delete from registry"`
-}
-
-// dockerConfig matches the structure of the docker config.json file, with just
-// the elements we are interested in.
-type dockerConfig struct {
-	Auths map[string]struct{ Auth string }
+	Format       string   `help:"Format output using a Go template, such as '{{.Repository}}:{{.Tag}} {{.Size | humanize}}'"`
+	PageSize     int32    `help:"Number of repositories or tags to request per page (0 for registry default)"`
+	Limit        int      `help:"Maximum number of tags to list per repository (0 for no limit)"`
+	Concurrency  int      `default:"8" help:"Maximum number of concurrent requests"`
 }
 
 func main() {
-	c := config{}
-	if err := kong.Parse(&c).Run(&c); err != nil {
-		handleError(err)
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	go func() {
+		// Restore the default signal behaviour so a second signal
+		// terminates dreg without waiting for requests in progress.
+		<-ctx.Done()
+		stop()
+	}()
+
+	c := config{ctx: ctx}
+	kctx := kong.Parse(&c)
+	if c.Deadline > 0 {
+		var cancel context.CancelFunc
+		c.ctx, cancel = context.WithTimeout(c.ctx, c.Deadline)
+		defer cancel()
+	}
+	if err := kctx.Run(&c); err != nil {
+		handleError(c.ctx, err)
 	}
 }
 
-func handleError(err error) {
+func handleError(ctx context.Context, err error) {
+	switch ctx.Err() {
+	case context.Canceled:
+		fmt.Fprintln(os.Stderr, "Interrupted")
+		os.Exit(1)
+	case context.DeadlineExceeded:
+		fmt.Fprintln(os.Stderr, "Deadline exceeded")
+		os.Exit(1)
+	}
 	st, ok := status.FromError(err)
 	if !ok {
 		fmt.Fprintln(os.Stderr, err)
@@ -82,72 +128,238 @@ func (c *config) AfterApply() error {
 		f.Close()
 	}
 
-	opts := []httprule.Option{}
-	if opt := authOpt(c.dcfg, c.URL); opt != nil {
-		opts = append(opts, opt)
+	client, err := c.newClient(c.URL)
+	if err != nil {
+		return err
+	}
+	c.client = client
+	return nil
+}
+
+// context returns the context for the requests of a command.
+func (c *config) context() context.Context {
+	if c.ctx == nil {
+		return context.Background()
 	}
+	return c.ctx
+}
 
-	cc := httprule.NewClientConn(c.URL, opts...)
-	c.client = pb.NewRegistryClient(cc)
+// newClient returns a registry client for the registry at registryURL,
+// authenticating with the credentials for its host in the docker config.
+func (c *config) newClient(registryURL string) (*registry.Client, error) {
+	rpc, err := registry.NewRegistryClient(registryURL, c.clientOptions()...)
+	if err != nil || c.NoCache {
+		return registry.New(rpc), err
+	}
+	dc, err := c.diskCache()
+	if err != nil {
+		// Without a cache directory, run uncached.
+		return registry.New(rpc), nil
+	}
+	u, _ := url.Parse(registryURL)
+	return registry.New(&cachedClient{RegistryClient: rpc, cache: dc, host: u.Host}), nil
+}
 
-	return nil
+// clientOptions returns the registry client options for the global flags.
+func (c *config) clientOptions() []registry.Option {
+	opts := []registry.Option{
+		registry.WithDockerConfig(c.dcfg),
+		registry.WithRetries(c.Retries),
+		registry.WithTimeout(c.Timeout),
+	}
+	if c.Verbose {
+		opts = append(opts, registry.WithLog(os.Stderr))
+	}
+	return opts
 }
 
-func authOpt(dcfg dockerConfig, regURL string) httprule.Option {
-	u, err := url.Parse(regURL)
+// diskCache returns the cache of manifests and blobs in the user cache
+// directory.
+func (c *config) diskCache() (*diskCache, error) {
+	dir, err := defaultCacheDir()
 	if err != nil {
-		return nil
+		return nil, fmt.Errorf("cannot find cache directory: %w", err)
 	}
-	// Only use auth on insecure http if localhost (loopback)
-	if u.Scheme == "http" {
-		ips, err := net.LookupIP(u.Host)
-		if err != nil {
-			return nil
-		}
-		for _, ip := range ips {
-			if !ip.IsLoopback() {
-				return nil
-			}
-		}
+	return &diskCache{dir: dir}, nil
+}
+
+// parseImage parses image as an image reference. References that name a
+// registry are normalised as docker does, so "docker.io/alpine" refers to
+// "library/alpine" on Docker Hub. References without a registry refer to
+// the --url registry and are used as is.
+func parseImage(image string) (reference.Reference, error) {
+	ref, err := reference.Parse(image)
+	if err != nil {
+		return reference.Reference{}, err
 	}
-	token, ok := dcfg.Auths[u.Host]
-	if !ok {
-		return nil
+	if ref.Domain != "" {
+		ref = ref.Normalized()
 	}
-	return httprule.WithHeader("Authorization", "Basic "+token.Auth)
+	return ref, nil
+}
+
+// resolveImage parses image as an image reference and returns it with a
+// client for its registry.
+func (c *config) resolveImage(image string) (*registry.Client, reference.Reference, error) {
+	ref, err := parseImage(image)
+	if err != nil {
+		return nil, reference.Reference{}, err
+	}
+	client, err := c.clientFor(ref)
+	if err != nil {
+		return nil, reference.Reference{}, err
+	}
+	return client, ref, nil
+}
+
+// clientFor returns a client for the registry of ref, creating and caching
+// one if it is not the --url registry.
+func (c *config) clientFor(ref reference.Reference) (*registry.Client, error) {
+	registryURL := c.registryURL(ref)
+	if registryURL == c.URL {
+		return c.client, nil
+	}
+	if client, ok := c.clients[registryURL]; ok {
+		return client, nil
+	}
+	client, err := c.newClient(registryURL)
+	if err != nil {
+		return nil, err
+	}
+	if c.clients == nil {
+		c.clients = map[string]*registry.Client{}
+	}
+	c.clients[registryURL] = client
+	return client, nil
+}
+
+// registryURL returns the URL of the registry of ref. It is the --url
+// registry if ref does not name a registry or names the host of --url.
+// Otherwise HTTPS is used, except for registries on the loopback
+// interface.
+func (c *config) registryURL(ref reference.Reference) string {
+	if ref.Domain == "" {
+		return c.URL
+	}
+	host := ref.Registry()
+	if u, err := url.Parse(c.URL); err == nil && u.Host == host {
+		return c.URL
+	}
+	if isLoopback(host) {
+		return "http://" + host
+	}
+	return "https://" + host
+}
+
+func isLoopback(host string) bool {
+	if h, _, err := net.SplitHostPort(host); err == nil {
+		host = h
+	}
+	host = strings.Trim(host, "[]")
+	if host == "localhost" {
+		return true
+	}
+	ip := net.ParseIP(host)
+	return ip != nil && ip.IsLoopback()
 }
 
 func (c *check) Run(cfg *config) error {
-	ctx := context.Background()
+	ctx := cfg.context()
 	req := &pb.CheckV2Request{}
 	if _, err := cfg.client.CheckV2(ctx, req); err != nil {
 		return err
 	}
 
+	if out := cfg.records(); out != nil {
+		if err := out.write(&pb.CheckRecord{Registry: cfg.URL, Ok: true}); err != nil {
+			return err
+		}
+		return out.close()
+	}
 	fmt.Println("OK")
 	return nil
 }
 
 // list.Run executes the list cli subcommand, listing the images in a registry.
 func (l *list) Run(cfg *config) error {
-	ctx := context.Background()
-	if len(l.Repositories) == 0 {
-		req := &pb.ListRepositoriesRequest{}
-		resp, err := cfg.client.ListRepositories(ctx, req)
+	ctx := cfg.context()
+	repos, err := listRepos(ctx, cfg, l.Repositories, l.PageSize)
+	if err != nil {
+		return err
+	}
+
+	var filter *pb.Platform
+	if l.Platform != "" {
+		var err error
+		if filter, err = registry.ParsePlatform(l.Platform); err != nil {
+			return err
+		}
+	}
+
+	out := cfg.records()
+	var format *listFormat
+	if l.Format != "" {
+		if out != nil || l.Table {
+			return errors.New("--format cannot be used with --output or --table")
+		}
+		if format, err = parseListFormat(l.Format); err != nil {
+			return err
+		}
+	}
+	// Image indexes are resolved to their platform images for sizes,
+	// platforms and created times, which take extra requests per tag.
+	platforms := l.Sizes || filter != nil || format.uses("Size", "Platform", "Created")
+	created := format.uses("Created") || (out != nil && platforms)
+	fetch := platforms || out != nil || format.uses("Digest", "MediaType")
+
+	// Tags of all repositories are listed, then the images of all tags
+	// are fetched if needed, with up to --concurrency requests at once.
+	clients := make([]*registry.Client, len(repos))
+	for i, repo := range repos {
+		if clients[i], err = cfg.clientFor(repo); err != nil {
+			return err
+		}
+	}
+	tags, err := listTags(ctx, clients, repos, l.PageSize, l.Limit, l.Concurrency)
+	if err != nil {
+		return err
+	}
+	var images []*listImage
+	for i, repo := range repos {
+		for _, tag := range tags[i] {
+			li := &listImage{
+				repo:    repo,
+				client:  clients[i],
+				tag:     tag,
+				records: []*pb.ImageRecord{{Repository: repo.Name(), Tag: tag}},
+			}
+			images = append(images, li)
+		}
+	}
+	if fetch {
+		p := newProgress("Fetching manifests", len(images))
+		err := parallel(ctx, len(images), l.Concurrency, func(ctx context.Context, i int) error {
+			li := images[i]
+			records, err := imageRecords(ctx, li.client, li.repo, li.tag, filter, platforms, created)
+			li.records = records
+			return err
+		}, p.inc)
+		p.clear()
 		if err != nil {
 			return err
 		}
-		l.Repositories = resp.Repositories
-		sort.Strings(l.Repositories)
 	}
 
 	sep := ':'
 	var w io.Writer = os.Stdout
-	if l.Table {
+	if l.Table && out == nil {
 		tw := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
 		defer tw.Flush()
 		w = tw
 		heading := "REPOSITORY\tTAG"
+		if l.Sizes || filter != nil {
+			heading += "\tPLATFORM"
+		}
 		if l.Sizes {
 			heading += "\tSIZE"
 		}
@@ -155,76 +367,159 @@ func (l *list) Run(cfg *config) error {
 		sep = '\t'
 	}
 
-	for _, name := range l.Repositories {
-		req := &pb.ListImageTagsRequest{Name: name}
-		resp, err := cfg.client.ListImageTags(ctx, req)
-		if err != nil {
-			return err
+	for _, li := range images {
+		if format == nil && !fetch {
+			fmt.Fprintf(w, "%s%c%s\n", li.repo.Name(), sep, li.tag)
+			continue
 		}
-
-		sort.Strings(resp.Tags)
-		for _, tag := range resp.Tags {
-			if !l.Sizes {
-				fmt.Fprintf(w, "%s%c%s\n", name, sep, tag)
-				continue
+		for _, r := range li.records {
+			switch {
+			case out != nil:
+				err = out.write(r)
+			case format != nil:
+				err = format.execute(w, r)
+			default:
+				l.printRecord(w, sep, r)
 			}
-			req := &pb.GetManifestRequest{Name: name, Reference: tag}
-			resp, err := cfg.client.GetManifest(ctx, req)
 			if err != nil {
 				return err
 			}
-			var size uint64
-			for _, layer := range resp.Manifest.Layers {
-				size += layer.Size
-			}
-			fmt.Fprintf(w, "%s%c%s\t%s\n", name, sep, tag, humanize.Bytes(size)+" (compressed)")
 		}
 	}
+	if out != nil {
+		return out.close()
+	}
 	return nil
 }
 
-func (r *rm) Run(cfg *config) error {
-	ctx := context.Background()
-	n := 0
-	for _, image := range r.Images {
-		if !strings.Contains(image, ":") {
-			image += ":latest"
-		}
-		parts := strings.SplitN(image, ":", 2)
-		req := &pb.GetDigestRequest{Name: parts[0], Reference: parts[1]}
-		resp, err := cfg.client.GetDigest(ctx, req)
-		if err != nil {
-			fmt.Fprintf(os.Stderr, "Couldn't find %s: %v\n", image, err)
-			continue
-		}
-		delReq := &pb.DeleteImageRequest{Name: parts[0], Reference: resp.Digest}
-		_, err = cfg.client.DeleteImage(ctx, delReq)
-		if err != nil {
-			fmt.Fprintf(os.Stderr, "Couldn't remove %s: %v\n", image, err)
-			continue
-		}
-		if cfg.Verbose {
-			fmt.Printf("%s removed\n", image)
+// listImage is a tag of a repository to list and its image records.
+type listImage struct {
+	repo    reference.Reference
+	client  *registry.Client
+	tag     string
+	records []*pb.ImageRecord
+}
+
+// imageRecords returns a record for the image repo:tag, or if platforms is
+// set, a record for each of its platform images that matches filter: each
+// platform of an image index, or an image manifest whose config platform
+// matches. Sizes are only set for platform records. The image configs are
+// fetched for the created time only if created is set.
+func imageRecords(ctx context.Context, client *registry.Client, repo reference.Reference, tag string, filter *pb.Platform, platforms, created bool) ([]*pb.ImageRecord, error) {
+	resp, err := client.Manifest(ctx, repo.Path, tag)
+	if err != nil {
+		return nil, err
+	}
+	digest := resp.Digest
+	if digest == "" {
+		digest = registry.SHA256Digest(resp.Raw)
+	}
+	record := &pb.ImageRecord{
+		Repository: repo.Name(),
+		Tag:        tag,
+		Digest:     digest,
+		MediaType:  registry.ManifestMediaType(resp.Manifest),
+	}
+	if !platforms {
+		return []*pb.ImageRecord{record}, nil
+	}
+	images, err := client.Images(ctx, repo.Path, resp, filter)
+	if err != nil {
+		return nil, err
+	}
+	result := make([]*pb.ImageRecord, 0, len(images))
+	for _, pi := range images {
+		r := proto.Clone(record).(*pb.ImageRecord)
+		r.Platform = registry.FormatPlatform(pi.Platform)
+		r.Size = registry.LayerSize(pi.Image)
+		if created {
+			config, _, err := client.ImageConfig(ctx, repo.Path, pi.Image)
+			if err != nil {
+				return nil, fmt.Errorf("%s:%s: %w", repo.Name(), tag, err)
+			}
+			r.Created = config.Created
 		}
-		n++
+		result = append(result, r)
 	}
+	return result, nil
+}
 
-	if n != len(r.Images) {
-		return fmt.Errorf("%d image(s) not removed", len(r.Images)-n)
+// printRecord prints the image record r as a line of text.
+func (l *list) printRecord(w io.Writer, sep rune, r *pb.ImageRecord) {
+	line := fmt.Sprintf("%s%c%s", r.Repository, sep, r.Tag)
+	if r.Platform != "" || l.Table {
+		line += "\t" + r.Platform
 	}
-	return nil
+	if l.Sizes {
+		line += "\t" + humanize.Bytes(r.Size) + " (compressed)"
+	}
+	fmt.Fprintln(w, line)
 }
 
 func (r *repos) Run(cfg *config) error {
-	ctx := context.Background()
-	req := &pb.ListRepositoriesRequest{}
-	resp, err := cfg.client.ListRepositories(ctx, req)
+	ctx := cfg.context()
+	repos, err := cfg.client.RepositoryIterator(r.PageSize).Collect(ctx, r.Limit)
 	if err != nil {
 		return err
 	}
-	sort.Strings(resp.Repositories)
-	for _, repo := range resp.Repositories {
-		fmt.Println(repo)
+	sort.Strings(repos)
+	out := cfg.records()
+	for _, repo := range repos {
+		if out == nil {
+			fmt.Println(repo)
+			continue
+		}
+		if err := out.write(&pb.RepositoryRecord{Repository: repo}); err != nil {
+			return err
+		}
+	}
+	if out != nil {
+		return out.close()
 	}
 	return nil
 }
+
+// listRepos returns the repositories given by name, or all repositories of
+// the --url registry if none are given.
+func listRepos(ctx context.Context, cfg *config, names []string, pageSize int32) ([]reference.Reference, error) {
+	var repos []reference.Reference
+	for _, name := range names {
+		ref, err := parseImage(name)
+		if err != nil {
+			return nil, err
+		}
+		repos = append(repos, ref)
+	}
+	if len(repos) > 0 {
+		return repos, nil
+	}
+	return catalog(ctx, cfg.client, pageSize)
+}
+
+// catalog returns all repositories of the registry of client, sorted.
+func catalog(ctx context.Context, client *registry.Client, pageSize int32) ([]reference.Reference, error) {
+	names, err := client.RepositoryIterator(pageSize).Collect(ctx, 0)
+	if err != nil {
+		return nil, err
+	}
+	sort.Strings(names)
+	repos := make([]reference.Reference, len(names))
+	for i, name := range names {
+		repos[i] = reference.Reference{Path: name}
+	}
+	return repos, nil
+}
+
+// listTags returns the sorted tags of each of repos, up to limit per
+// repository if it is not zero, listing the repositories concurrently.
+// clients are the clients for the registries of repos.
+func listTags(ctx context.Context, clients []*registry.Client, repos []reference.Reference, pageSize int32, limit, concurrency int) ([][]string, error) {
+	tags := make([][]string, len(repos))
+	err := parallel(ctx, len(repos), concurrency, func(ctx context.Context, i int) error {
+		t, err := clients[i].TagIterator(repos[i].Path, pageSize).Collect(ctx, limit)
+		sort.Strings(t)
+		tags[i] = t
+		return err
+	}, nil)
+	return tags, err
+}
@@ -0,0 +1,213 @@
+package main
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"gopkg.in/yaml.v3"
+)
+
+// recordWriter writes records in the --output format: a JSON array, JSON
+// lines, a YAML sequence, or CSV with a header row of field names. Field
+// names are the protojson names of the record fields.
+type recordWriter struct {
+	format string
+	out    io.Writer
+	// records are buffered for formats that are not streamed.
+	records []json.RawMessage
+	csv     *csv.Writer
+	header  bool
+}
+
+// records returns a writer for records to stdout, or nil if the output
+// format is text.
+func (c *config) records() *recordWriter {
+	if c.Output == "" || c.Output == "text" {
+		return nil
+	}
+	return newRecordWriter(c.Output, os.Stdout)
+}
+
+func newRecordWriter(format string, out io.Writer) *recordWriter {
+	return &recordWriter{format: format, out: out, csv: csv.NewWriter(out)}
+}
+
+// writeRecords writes records to out and closes it.
+func writeRecords(out *recordWriter, records ...proto.Message) error {
+	for _, r := range records {
+		if err := out.write(r); err != nil {
+			return err
+		}
+	}
+	return out.close()
+}
+
+// write writes the record m.
+func (w *recordWriter) write(m proto.Message) error {
+	if w.format == "csv" {
+		return w.writeCSV(m.ProtoReflect())
+	}
+	b, err := protojson.MarshalOptions{EmitUnpopulated: true}.Marshal(m)
+	if err != nil {
+		return err
+	}
+	if b, err = int64Numbers(b, m.ProtoReflect()); err != nil {
+		return err
+	}
+	return w.writeJSON(b)
+}
+
+// int64Numbers rewrites the 64-bit integer fields of the record m in its
+// protojson encoding b as JSON numbers rather than the strings protojson
+// uses, so that sizes can be used as numbers. Records are flat, so nested
+// messages are not rewritten.
+func int64Numbers(b []byte, m protoreflect.Message) ([]byte, error) {
+	dec := json.NewDecoder(bytes.NewReader(b))
+	if _, err := dec.Token(); err != nil {
+		return nil, err
+	}
+	fields := m.Descriptor().Fields()
+	buf := &bytes.Buffer{}
+	buf.WriteByte('{')
+	for dec.More() {
+		key, err := dec.Token()
+		if err != nil {
+			return nil, err
+		}
+		var val json.RawMessage
+		if err := dec.Decode(&val); err != nil {
+			return nil, err
+		}
+		if fd := fields.ByJSONName(key.(string)); fd != nil && !fd.IsList() && is64BitInt(fd.Kind()) {
+			var s string
+			if json.Unmarshal(val, &s) == nil {
+				val = json.RawMessage(s)
+			}
+		}
+		if buf.Len() > 1 {
+			buf.WriteByte(',')
+		}
+		k, _ := json.Marshal(key)
+		buf.Write(k)
+		buf.WriteByte(':')
+		buf.Write(val)
+	}
+	buf.WriteByte('}')
+	return buf.Bytes(), nil
+}
+
+func is64BitInt(k protoreflect.Kind) bool {
+	switch k {
+	case protoreflect.Int64Kind, protoreflect.Sint64Kind, protoreflect.Sfixed64Kind,
+		protoreflect.Uint64Kind, protoreflect.Fixed64Kind:
+		return true
+	}
+	return false
+}
+
+// writeJSON writes a record given as a JSON object. It is not supported
+// for CSV output.
+func (w *recordWriter) writeJSON(b []byte) error {
+	switch w.format {
+	case "jsonl":
+		buf := &bytes.Buffer{}
+		if err := json.Compact(buf, b); err != nil {
+			return err
+		}
+		buf.WriteByte('\n')
+		_, err := w.out.Write(buf.Bytes())
+		return err
+	case "json", "yaml":
+		w.records = append(w.records, b)
+		return nil
+	}
+	return fmt.Errorf("%s output is not supported for this command", w.format)
+}
+
+func (w *recordWriter) writeCSV(m protoreflect.Message) error {
+	fields := m.Descriptor().Fields()
+	if !w.header {
+		header := make([]string, fields.Len())
+		for i := range header {
+			header[i] = fields.Get(i).JSONName()
+		}
+		if err := w.csv.Write(header); err != nil {
+			return err
+		}
+		w.header = true
+	}
+	row := make([]string, fields.Len())
+	for i := range row {
+		fd := fields.Get(i)
+		v := m.Get(fd)
+		if fd.Message() == nil {
+			row[i] = fmt.Sprint(v.Interface())
+			continue
+		}
+		if !m.Has(fd) {
+			continue
+		}
+		// Well-known types such as timestamps are JSON strings.
+		b, err := protojson.Marshal(v.Message().Interface())
+		if err != nil {
+			return err
+		}
+		row[i] = strings.Trim(string(b), `"`)
+	}
+	return w.csv.Write(row)
+}
+
+// close writes any buffered records. It must be called after the last
+// record is written.
+func (w *recordWriter) close() error {
+	switch w.format {
+	case "json":
+		records := w.records
+		if records == nil {
+			records = []json.RawMessage{}
+		}
+		b, err := json.MarshalIndent(records, "", "  ")
+		if err != nil {
+			return err
+		}
+		_, err = fmt.Fprintln(w.out, string(b))
+		return err
+	case "yaml":
+		seq := &yaml.Node{Kind: yaml.SequenceNode, Tag: "!!seq"}
+		for _, b := range w.records {
+			var doc yaml.Node
+			if err := yaml.Unmarshal(b, &doc); err != nil {
+				return err
+			}
+			seq.Content = append(seq.Content, blockStyle(doc.Content[0]))
+		}
+		enc := yaml.NewEncoder(w.out)
+		enc.SetIndent(2)
+		if err := enc.Encode(seq); err != nil {
+			return err
+		}
+		return enc.Close()
+	case "csv":
+		w.csv.Flush()
+		return w.csv.Error()
+	}
+	return nil
+}
+
+// blockStyle clears the flow and quoting styles of n and its children, as
+// parsed from JSON, so n is encoded as idiomatic block YAML.
+func blockStyle(n *yaml.Node) *yaml.Node {
+	n.Style = 0
+	for _, c := range n.Content {
+		blockStyle(c)
+	}
+	return n
+}
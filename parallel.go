@@ -0,0 +1,103 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"sync"
+)
+
+// parallel calls fn for each i in [0, n) with at most concurrency calls
+// running at once. The first error returned by fn cancels the context
+// passed to the other calls and is returned once all running calls have
+// returned. done, if not nil, is called after each successful call.
+func parallel(ctx context.Context, n, concurrency int, fn func(ctx context.Context, i int) error, done func()) error {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	if concurrency < 1 {
+		concurrency = 1
+	}
+
+	var (
+		wg       sync.WaitGroup
+		mu       sync.Mutex
+		firstErr error
+	)
+	sem := make(chan struct{}, concurrency)
+	for i := 0; i < n; i++ {
+		select {
+		case sem <- struct{}{}:
+		case <-ctx.Done():
+		}
+		if ctx.Err() != nil {
+			break
+		}
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			defer func() { <-sem }()
+			err := fn(ctx, i)
+			mu.Lock()
+			defer mu.Unlock()
+			if err != nil {
+				if firstErr == nil {
+					firstErr = err
+					cancel()
+				}
+				return
+			}
+			if done != nil {
+				done()
+			}
+		}(i)
+	}
+	wg.Wait()
+	if firstErr != nil {
+		return firstErr
+	}
+	return ctx.Err()
+}
+
+// progress shows a count of completed items on a line of a terminal. It
+// shows nothing if out is not a terminal.
+type progress struct {
+	out   io.Writer
+	label string
+	total int
+	n     int
+}
+
+func newProgress(label string, total int) *progress {
+	if !isTerminal(os.Stderr) {
+		return &progress{}
+	}
+	p := &progress{out: os.Stderr, label: label, total: total}
+	p.print()
+	return p
+}
+
+// inc increments the count of completed items.
+func (p *progress) inc() {
+	if p.out == nil {
+		return
+	}
+	p.n++
+	p.print()
+}
+
+func (p *progress) print() {
+	fmt.Fprintf(p.out, "\r%s %d/%d", p.label, p.n, p.total)
+}
+
+// clear erases the progress line.
+func (p *progress) clear() {
+	if p.out != nil {
+		fmt.Fprint(p.out, "\r\033[K")
+	}
+}
+
+func isTerminal(f *os.File) bool {
+	fi, err := f.Stat()
+	return err == nil && fi.Mode()&os.ModeCharDevice != 0
+}
@@ -22,15 +22,44 @@ type RegistryClient interface {
 	// https://docs.docker.com/registry/spec/api/#api-version-check
 	CheckV2(ctx context.Context, in *CheckV2Request, opts ...grpc.CallOption) (*CheckV2Response, error)
 	// https://docs.docker.com/registry/spec/api/#listing-repositories
+	// https://docs.docker.com/registry/spec/api/#pagination
 	ListRepositories(ctx context.Context, in *ListRepositoriesRequest, opts ...grpc.CallOption) (*ListRepositoriesResponse, error)
 	// https://docs.docker.com/registry/spec/api/#listing-image-tags
+	// https://docs.docker.com/registry/spec/api/#pagination-1
 	ListImageTags(ctx context.Context, in *ListImageTagsRequest, opts ...grpc.CallOption) (*ListImageTagsResponse, error)
 	// https://docs.docker.com/registry/spec/api/#manifest
 	// https://docs.docker.com/registry/spec/manifest-v2-2/#image-manifest-field-descriptions
 	GetDigest(ctx context.Context, in *GetDigestRequest, opts ...grpc.CallOption) (*GetDigestResponse, error)
 	// https://docs.docker.com/registry/spec/api/#manifest
 	// https://docs.docker.com/registry/spec/manifest-v2-2/#image-manifest-field-descriptions
+	// https://github.com/opencontainers/image-spec/blob/main/manifest.md
+	// https://github.com/opencontainers/image-spec/blob/main/image-index.md
+	// The manifest body is returned unparsed in `raw` as its exact bytes
+	// determine its digest.
 	GetManifest(ctx context.Context, in *GetManifestRequest, opts ...grpc.CallOption) (*GetManifestResponse, error)
+	// https://docs.docker.com/registry/spec/api/#existing-layers
+	HeadBlob(ctx context.Context, in *HeadBlobRequest, opts ...grpc.CallOption) (*HeadBlobResponse, error)
+	// https://docs.docker.com/registry/spec/api/#pulling-a-layer
+	// https://docs.docker.com/registry/spec/api/#fetch-blob-part
+	// The blob is streamed in chunks, starting at offset if set.
+	GetBlob(ctx context.Context, in *GetBlobRequest, opts ...grpc.CallOption) (Registry_GetBlobClient, error)
+	// https://docs.docker.com/registry/spec/api/#deleting-a-layer
+	DeleteBlob(ctx context.Context, in *DeleteBlobRequest, opts ...grpc.CallOption) (*DeleteBlobResponse, error)
+	// https://docs.docker.com/registry/spec/api/#starting-an-upload
+	// https://docs.docker.com/registry/spec/api/#cross-repository-blob-mount
+	// If mount and from are set and the blob is mounted, the response digest
+	// is set and there is no upload to continue.
+	StartBlobUpload(ctx context.Context, in *StartBlobUploadRequest, opts ...grpc.CallOption) (*StartBlobUploadResponse, error)
+	// https://docs.docker.com/registry/spec/api/#chunked-upload
+	// The request is sent to location, from the response to the previous
+	// upload request, as it may carry upload state.
+	UploadBlobChunk(ctx context.Context, in *UploadBlobChunkRequest, opts ...grpc.CallOption) (*UploadBlobChunkResponse, error)
+	// https://docs.docker.com/registry/spec/api/#completed-upload
+	// https://docs.docker.com/registry/spec/api/#monolithic-upload
+	// Any data not sent with UploadBlobChunk is sent in the request body.
+	CompleteBlobUpload(ctx context.Context, in *CompleteBlobUploadRequest, opts ...grpc.CallOption) (*CompleteBlobUploadResponse, error)
+	// https://docs.docker.com/registry/spec/api/#pushing-an-image-manifest
+	PutManifest(ctx context.Context, in *PutManifestRequest, opts ...grpc.CallOption) (*PutManifestResponse, error)
 	// https://docs.docker.com/registry/spec/api/#deleting-an-image
 	DeleteImage(ctx context.Context, in *DeleteImageRequest, opts ...grpc.CallOption) (*DeleteImageResponse, error)
 }
@@ -88,6 +117,92 @@ func (c *registryClient) GetManifest(ctx context.Context, in *GetManifestRequest
 	return out, nil
 }
 
+func (c *registryClient) HeadBlob(ctx context.Context, in *HeadBlobRequest, opts ...grpc.CallOption) (*HeadBlobResponse, error) {
+	out := new(HeadBlobResponse)
+	err := c.cc.Invoke(ctx, "/foxygoat.dreg.Registry/HeadBlob", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *registryClient) GetBlob(ctx context.Context, in *GetBlobRequest, opts ...grpc.CallOption) (Registry_GetBlobClient, error) {
+	stream, err := c.cc.NewStream(ctx, &Registry_ServiceDesc.Streams[0], "/foxygoat.dreg.Registry/GetBlob", opts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &registryGetBlobClient{stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+type Registry_GetBlobClient interface {
+	Recv() (*GetBlobResponse, error)
+	grpc.ClientStream
+}
+
+type registryGetBlobClient struct {
+	grpc.ClientStream
+}
+
+func (x *registryGetBlobClient) Recv() (*GetBlobResponse, error) {
+	m := new(GetBlobResponse)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+func (c *registryClient) DeleteBlob(ctx context.Context, in *DeleteBlobRequest, opts ...grpc.CallOption) (*DeleteBlobResponse, error) {
+	out := new(DeleteBlobResponse)
+	err := c.cc.Invoke(ctx, "/foxygoat.dreg.Registry/DeleteBlob", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *registryClient) StartBlobUpload(ctx context.Context, in *StartBlobUploadRequest, opts ...grpc.CallOption) (*StartBlobUploadResponse, error) {
+	out := new(StartBlobUploadResponse)
+	err := c.cc.Invoke(ctx, "/foxygoat.dreg.Registry/StartBlobUpload", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *registryClient) UploadBlobChunk(ctx context.Context, in *UploadBlobChunkRequest, opts ...grpc.CallOption) (*UploadBlobChunkResponse, error) {
+	out := new(UploadBlobChunkResponse)
+	err := c.cc.Invoke(ctx, "/foxygoat.dreg.Registry/UploadBlobChunk", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *registryClient) CompleteBlobUpload(ctx context.Context, in *CompleteBlobUploadRequest, opts ...grpc.CallOption) (*CompleteBlobUploadResponse, error) {
+	out := new(CompleteBlobUploadResponse)
+	err := c.cc.Invoke(ctx, "/foxygoat.dreg.Registry/CompleteBlobUpload", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *registryClient) PutManifest(ctx context.Context, in *PutManifestRequest, opts ...grpc.CallOption) (*PutManifestResponse, error) {
+	out := new(PutManifestResponse)
+	err := c.cc.Invoke(ctx, "/foxygoat.dreg.Registry/PutManifest", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 func (c *registryClient) DeleteImage(ctx context.Context, in *DeleteImageRequest, opts ...grpc.CallOption) (*DeleteImageResponse, error) {
 	out := new(DeleteImageResponse)
 	err := c.cc.Invoke(ctx, "/foxygoat.dreg.Registry/DeleteImage", in, out, opts...)
@@ -104,15 +219,44 @@ type RegistryServer interface {
 	// https://docs.docker.com/registry/spec/api/#api-version-check
 	CheckV2(context.Context, *CheckV2Request) (*CheckV2Response, error)
 	// https://docs.docker.com/registry/spec/api/#listing-repositories
+	// https://docs.docker.com/registry/spec/api/#pagination
 	ListRepositories(context.Context, *ListRepositoriesRequest) (*ListRepositoriesResponse, error)
 	// https://docs.docker.com/registry/spec/api/#listing-image-tags
+	// https://docs.docker.com/registry/spec/api/#pagination-1
 	ListImageTags(context.Context, *ListImageTagsRequest) (*ListImageTagsResponse, error)
 	// https://docs.docker.com/registry/spec/api/#manifest
 	// https://docs.docker.com/registry/spec/manifest-v2-2/#image-manifest-field-descriptions
 	GetDigest(context.Context, *GetDigestRequest) (*GetDigestResponse, error)
 	// https://docs.docker.com/registry/spec/api/#manifest
 	// https://docs.docker.com/registry/spec/manifest-v2-2/#image-manifest-field-descriptions
+	// https://github.com/opencontainers/image-spec/blob/main/manifest.md
+	// https://github.com/opencontainers/image-spec/blob/main/image-index.md
+	// The manifest body is returned unparsed in `raw` as its exact bytes
+	// determine its digest.
 	GetManifest(context.Context, *GetManifestRequest) (*GetManifestResponse, error)
+	// https://docs.docker.com/registry/spec/api/#existing-layers
+	HeadBlob(context.Context, *HeadBlobRequest) (*HeadBlobResponse, error)
+	// https://docs.docker.com/registry/spec/api/#pulling-a-layer
+	// https://docs.docker.com/registry/spec/api/#fetch-blob-part
+	// The blob is streamed in chunks, starting at offset if set.
+	GetBlob(*GetBlobRequest, Registry_GetBlobServer) error
+	// https://docs.docker.com/registry/spec/api/#deleting-a-layer
+	DeleteBlob(context.Context, *DeleteBlobRequest) (*DeleteBlobResponse, error)
+	// https://docs.docker.com/registry/spec/api/#starting-an-upload
+	// https://docs.docker.com/registry/spec/api/#cross-repository-blob-mount
+	// If mount and from are set and the blob is mounted, the response digest
+	// is set and there is no upload to continue.
+	StartBlobUpload(context.Context, *StartBlobUploadRequest) (*StartBlobUploadResponse, error)
+	// https://docs.docker.com/registry/spec/api/#chunked-upload
+	// The request is sent to location, from the response to the previous
+	// upload request, as it may carry upload state.
+	UploadBlobChunk(context.Context, *UploadBlobChunkRequest) (*UploadBlobChunkResponse, error)
+	// https://docs.docker.com/registry/spec/api/#completed-upload
+	// https://docs.docker.com/registry/spec/api/#monolithic-upload
+	// Any data not sent with UploadBlobChunk is sent in the request body.
+	CompleteBlobUpload(context.Context, *CompleteBlobUploadRequest) (*CompleteBlobUploadResponse, error)
+	// https://docs.docker.com/registry/spec/api/#pushing-an-image-manifest
+	PutManifest(context.Context, *PutManifestRequest) (*PutManifestResponse, error)
 	// https://docs.docker.com/registry/spec/api/#deleting-an-image
 	DeleteImage(context.Context, *DeleteImageRequest) (*DeleteImageResponse, error)
 	mustEmbedUnimplementedRegistryServer()
@@ -137,6 +281,27 @@ func (UnimplementedRegistryServer) GetDigest(context.Context, *GetDigestRequest)
 func (UnimplementedRegistryServer) GetManifest(context.Context, *GetManifestRequest) (*GetManifestResponse, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method GetManifest not implemented")
 }
+func (UnimplementedRegistryServer) HeadBlob(context.Context, *HeadBlobRequest) (*HeadBlobResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method HeadBlob not implemented")
+}
+func (UnimplementedRegistryServer) GetBlob(*GetBlobRequest, Registry_GetBlobServer) error {
+	return status.Errorf(codes.Unimplemented, "method GetBlob not implemented")
+}
+func (UnimplementedRegistryServer) DeleteBlob(context.Context, *DeleteBlobRequest) (*DeleteBlobResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method DeleteBlob not implemented")
+}
+func (UnimplementedRegistryServer) StartBlobUpload(context.Context, *StartBlobUploadRequest) (*StartBlobUploadResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method StartBlobUpload not implemented")
+}
+func (UnimplementedRegistryServer) UploadBlobChunk(context.Context, *UploadBlobChunkRequest) (*UploadBlobChunkResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method UploadBlobChunk not implemented")
+}
+func (UnimplementedRegistryServer) CompleteBlobUpload(context.Context, *CompleteBlobUploadRequest) (*CompleteBlobUploadResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method CompleteBlobUpload not implemented")
+}
+func (UnimplementedRegistryServer) PutManifest(context.Context, *PutManifestRequest) (*PutManifestResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method PutManifest not implemented")
+}
 func (UnimplementedRegistryServer) DeleteImage(context.Context, *DeleteImageRequest) (*DeleteImageResponse, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method DeleteImage not implemented")
 }
@@ -243,6 +408,135 @@ func _Registry_GetManifest_Handler(srv interface{}, ctx context.Context, dec fun
 	return interceptor(ctx, in, info, handler)
 }
 
+func _Registry_HeadBlob_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(HeadBlobRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(RegistryServer).HeadBlob(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/foxygoat.dreg.Registry/HeadBlob",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(RegistryServer).HeadBlob(ctx, req.(*HeadBlobRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Registry_GetBlob_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(GetBlobRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(RegistryServer).GetBlob(m, &registryGetBlobServer{stream})
+}
+
+type Registry_GetBlobServer interface {
+	Send(*GetBlobResponse) error
+	grpc.ServerStream
+}
+
+type registryGetBlobServer struct {
+	grpc.ServerStream
+}
+
+func (x *registryGetBlobServer) Send(m *GetBlobResponse) error {
+	return x.ServerStream.SendMsg(m)
+}
+
+func _Registry_DeleteBlob_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(DeleteBlobRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(RegistryServer).DeleteBlob(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/foxygoat.dreg.Registry/DeleteBlob",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(RegistryServer).DeleteBlob(ctx, req.(*DeleteBlobRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Registry_StartBlobUpload_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(StartBlobUploadRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(RegistryServer).StartBlobUpload(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/foxygoat.dreg.Registry/StartBlobUpload",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(RegistryServer).StartBlobUpload(ctx, req.(*StartBlobUploadRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Registry_UploadBlobChunk_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(UploadBlobChunkRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(RegistryServer).UploadBlobChunk(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/foxygoat.dreg.Registry/UploadBlobChunk",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(RegistryServer).UploadBlobChunk(ctx, req.(*UploadBlobChunkRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Registry_CompleteBlobUpload_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(CompleteBlobUploadRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(RegistryServer).CompleteBlobUpload(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/foxygoat.dreg.Registry/CompleteBlobUpload",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(RegistryServer).CompleteBlobUpload(ctx, req.(*CompleteBlobUploadRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Registry_PutManifest_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(PutManifestRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(RegistryServer).PutManifest(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/foxygoat.dreg.Registry/PutManifest",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(RegistryServer).PutManifest(ctx, req.(*PutManifestRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
 func _Registry_DeleteImage_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
 	in := new(DeleteImageRequest)
 	if err := dec(in); err != nil {
@@ -288,11 +582,41 @@ var Registry_ServiceDesc = grpc.ServiceDesc{
 			MethodName: "GetManifest",
 			Handler:    _Registry_GetManifest_Handler,
 		},
+		{
+			MethodName: "HeadBlob",
+			Handler:    _Registry_HeadBlob_Handler,
+		},
+		{
+			MethodName: "DeleteBlob",
+			Handler:    _Registry_DeleteBlob_Handler,
+		},
+		{
+			MethodName: "StartBlobUpload",
+			Handler:    _Registry_StartBlobUpload_Handler,
+		},
+		{
+			MethodName: "UploadBlobChunk",
+			Handler:    _Registry_UploadBlobChunk_Handler,
+		},
+		{
+			MethodName: "CompleteBlobUpload",
+			Handler:    _Registry_CompleteBlobUpload_Handler,
+		},
+		{
+			MethodName: "PutManifest",
+			Handler:    _Registry_PutManifest_Handler,
+		},
 		{
 			MethodName: "DeleteImage",
 			Handler:    _Registry_DeleteImage_Handler,
 		},
 	},
-	Streams:  []grpc.StreamDesc{},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "GetBlob",
+			Handler:       _Registry_GetBlob_Handler,
+			ServerStreams: true,
+		},
+	},
 	Metadata: "registry.proto",
 }
@@ -0,0 +1,146 @@
+package main
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"os"
+	"regexp"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// retentionPolicy is a policy file for prune mapping repositories to
+// retention rules, such as:
+//
+//	policies:
+//	  - repositories: ["ci/*", "team-a/**"]
+//	    keep-last: 10
+//	    max-age: 30d
+//	    keep-semver: true
+//	    never-delete: ["^latest$", "^release-"]
+//
+// The rules of the first policy with a repository glob matching a
+// repository are applied to it. Repositories matching no policy are not
+// pruned. JSON policy files use the same field names.
+type retentionPolicy struct {
+	Policies []*policyRule `yaml:"policies"`
+}
+
+// policyRule is the retention rules for the repositories matching any of
+// its globs. In globs, "*" matches within a path component and "**"
+// matches across path components.
+type policyRule struct {
+	Repositories []string `yaml:"repositories"`
+	KeepLast     int      `yaml:"keep-last"`
+	MaxAge       string   `yaml:"max-age"`
+	KeepSemver   bool     `yaml:"keep-semver"`
+	Include      string   `yaml:"include"`
+	NeverDelete  []string `yaml:"never-delete"`
+
+	globs []*regexp.Regexp
+	rules *retentionRules
+}
+
+// loadPolicy reads and validates the policy file filename, which may be
+// YAML or JSON.
+func loadPolicy(filename string) (*retentionPolicy, error) {
+	b, err := os.ReadFile(filename)
+	if err != nil {
+		return nil, err
+	}
+	policy := &retentionPolicy{}
+	dec := yaml.NewDecoder(bytes.NewReader(b))
+	dec.KnownFields(true)
+	if err := dec.Decode(policy); err != nil {
+		return nil, fmt.Errorf("%s: invalid policy: %w", filename, err)
+	}
+	if len(policy.Policies) == 0 {
+		return nil, fmt.Errorf("%s: invalid policy: no policies", filename)
+	}
+	for i, rule := range policy.Policies {
+		if err := rule.compile(); err != nil {
+			return nil, fmt.Errorf("%s: invalid policy %d: %w", filename, i+1, err)
+		}
+	}
+	return policy, nil
+}
+
+func (p *policyRule) compile() error {
+	if len(p.Repositories) == 0 {
+		return errors.New("repositories: at least one repository glob is required")
+	}
+	for _, glob := range p.Repositories {
+		if glob == "" {
+			return errors.New("repositories: empty glob")
+		}
+		p.globs = append(p.globs, globRegexp(glob))
+	}
+	rules := &retentionRules{keepLast: p.KeepLast, keepSemver: p.KeepSemver, protect: map[string]bool{}}
+	if p.KeepLast < 0 {
+		return fmt.Errorf("keep-last: must not be negative, got %d", p.KeepLast)
+	}
+	if p.MaxAge != "" {
+		d, err := parseAge(p.MaxAge)
+		if err != nil {
+			return fmt.Errorf("max-age: %w", err)
+		}
+		rules.maxAge = d
+	}
+	if p.Include != "" {
+		re, err := regexp.Compile(p.Include)
+		if err != nil {
+			return fmt.Errorf("include: %w", err)
+		}
+		rules.include = re
+	}
+	for _, pattern := range p.NeverDelete {
+		re, err := regexp.Compile(pattern)
+		if err != nil {
+			return fmt.Errorf("never-delete: %w", err)
+		}
+		rules.exclude = append(rules.exclude, re)
+	}
+	if rules.keepLast == 0 && rules.maxAge == 0 && rules.include == nil {
+		return errors.New("no retention rule: at least one of keep-last, max-age or include is required")
+	}
+	p.rules = rules
+	return nil
+}
+
+// rulesFor returns the retention rules of the first policy matching repo,
+// or nil if there is none.
+func (p *retentionPolicy) rulesFor(repo string) *retentionRules {
+	for _, rule := range p.Policies {
+		for _, glob := range rule.globs {
+			if glob.MatchString(repo) {
+				return rule.rules
+			}
+		}
+	}
+	return nil
+}
+
+// globRegexp returns a regexp matching the same strings as glob, where "*"
+// matches any characters other than "/", "**" matches any characters and
+// "?" matches any character other than "/".
+func globRegexp(glob string) *regexp.Regexp {
+	var sb strings.Builder
+	sb.WriteString("^")
+	for i := 0; i < len(glob); i++ {
+		switch {
+		case strings.HasPrefix(glob[i:], "**"):
+			sb.WriteString(".*")
+			i++
+		case glob[i] == '*':
+			sb.WriteString("[^/]*")
+		case glob[i] == '?':
+			sb.WriteString("[^/]")
+		default:
+			sb.WriteString(regexp.QuoteMeta(glob[i : i+1]))
+		}
+	}
+	sb.WriteString("$")
+	return regexp.MustCompile(sb.String())
+}
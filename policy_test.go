@@ -0,0 +1,92 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+// writePolicy writes a policy file with the given contents and returns its
+// name.
+func writePolicy(t *testing.T, policy string) string {
+	t.Helper()
+	filename := filepath.Join(t.TempDir(), "policy.yaml")
+	if err := os.WriteFile(filename, []byte(policy), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	return filename
+}
+
+func TestLoadPolicy(t *testing.T) {
+	policy, err := loadPolicy(writePolicy(t, `
+policies:
+  - repositories: ["ci/*", "team-a/**"]
+    keep-last: 10
+    max-age: 30d
+    keep-semver: true
+    never-delete: ["^latest$", "^release-"]
+  - repositories: ["ci/**"]
+    include: "^pr-"
+`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, repo := range []string{"ci/app", "team-a/x", "team-a/x/y"} {
+		r := policy.rulesFor(repo)
+		if r == nil {
+			t.Fatalf("%s: no rules", repo)
+		}
+		if r.keepLast != 10 || r.maxAge != 30*24*time.Hour || !r.keepSemver || r.include != nil {
+			t.Errorf("%s: got rules %+v", repo, r)
+		}
+		if !r.isExcluded("latest") || !r.isExcluded("release-1") || r.isExcluded("latest-1") {
+			t.Errorf("%s: never-delete not applied", repo)
+		}
+	}
+	// The first matching policy applies.
+	if r := policy.rulesFor("ci/app/cache"); r == nil || r.include == nil || r.keepLast != 0 {
+		t.Errorf("ci/app/cache: got rules %+v", r)
+	}
+	for _, repo := range []string{"ci", "team-b/x", "app"} {
+		if r := policy.rulesFor(repo); r != nil {
+			t.Errorf("%s: got rules %+v, want none", repo, r)
+		}
+	}
+
+	policy, err = loadPolicy(writePolicy(t, `{"policies": [{"repositories": ["a"], "keep-last": 3}]}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if r := policy.rulesFor("a"); r == nil || r.keepLast != 3 {
+		t.Errorf("JSON policy: got rules %+v", r)
+	}
+}
+
+func TestLoadPolicyErrors(t *testing.T) {
+	tests := map[string]string{
+		`{}`:                         "no policies",
+		`policies: []`:               "no policies",
+		`policies: [{keep-last: 1}]`: "at least one repository glob",
+		`policies: [{repositories: [""], keep-last: 1}]`:                     "empty glob",
+		`policies: [{repositories: [a], keepLast: 1}]`:                       "keepLast not found",
+		`policies: [{repositories: [a], keep-last: -1}]`:                     "must not be negative",
+		`policies: [{repositories: [a], max-age: 3x}]`:                       "max-age",
+		`policies: [{repositories: [a], include: "("}]`:                      "include",
+		`policies: [{repositories: [a], keep-last: 1, never-delete: ["("]}]`: "never-delete",
+		`policies: [{repositories: [a]}]`:                                    "no retention rule",
+		`policies: [{repositories: [a], keep-semver: true}]`:                 "no retention rule",
+		`{"policies": [{"repositories": ["a"], "keep-last": "x"}]}`:          "invalid policy",
+		`policies: [{repositories: [a], keep-last: 1}, {repositories: [b]}]`: "invalid policy 2",
+	}
+	for policy, want := range tests {
+		_, err := loadPolicy(writePolicy(t, policy))
+		if err == nil || !strings.Contains(err.Error(), want) {
+			t.Errorf("%s: got error %v, want %q", policy, err, want)
+		}
+	}
+	if _, err := loadPolicy(filepath.Join(t.TempDir(), "missing.yaml")); !os.IsNotExist(err) {
+		t.Errorf("missing file: got error %v", err)
+	}
+}
@@ -0,0 +1,541 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"regexp"
+	"sort"
+	"strconv"
+	"text/tabwriter"
+	"time"
+
+	"foxygo.at/dreg/pb"
+	"foxygo.at/dreg/reference"
+	"foxygo.at/dreg/registry"
+	"github.com/dustin/go-humanize"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+type prune struct {
+	Repositories []string `arg:"" optional:"" name:"repository" help:"Repositories to prune, optionally prefixed with registry host (default all)"`
+	Policy       string   `type:"path" help:"YAML or JSON policy file with retention rules by repository, instead of rule flags"`
+	KeepLast     int      `help:"Keep the N most recently created tags per repository"`
+	OlderThan    string   `help:"Only delete tags created longer ago than this duration, e.g. 720h, 30d or 4w"`
+	Include      string   `help:"Only delete tags matching this regexp"`
+	Exclude      string   `help:"Never delete tags matching this regexp"`
+	Protect      []string `help:"Tags never to delete"`
+	Untag        bool     `help:"Delete tags sharing a manifest with kept tags by deleting only the tag, for registries that support deleting tags"`
+	DryRun       bool     `default:"true" negatable:"" help:"Only show what would be deleted (use --no-dry-run to delete)"`
+	PageSize     int32    `help:"Number of repositories or tags to request per page (0 for registry default)"`
+	Concurrency  int      `default:"8" help:"Maximum number of concurrent requests"`
+}
+
+// retentionRules select the tags of a repository to delete. Tags that are
+// protected, match exclude, are semantic versions if keepSemver is set or
+// do not match include are always kept. Of the remaining tags, the keepLast
+// most recently created are kept, and the rest deleted if they were created
+// before maxAge ago.
+type retentionRules struct {
+	keepLast   int
+	maxAge     time.Duration
+	keepSemver bool
+	include    *regexp.Regexp
+	exclude    []*regexp.Regexp
+	protect    map[string]bool
+}
+
+// semverRE matches semantic versions, optionally prefixed with "v".
+// https://semver.org/#is-there-a-suggested-regular-expression-regex-to-check-a-semver-string
+var semverRE = regexp.MustCompile(`^v?(0|[1-9]\d*)\.(0|[1-9]\d*)\.(0|[1-9]\d*)(?:-((?:0|[1-9]\d*|\d*[a-zA-Z-][0-9a-zA-Z-]*)(?:\.(?:0|[1-9]\d*|\d*[a-zA-Z-][0-9a-zA-Z-]*))*))?(?:\+([0-9a-zA-Z-]+(?:\.[0-9a-zA-Z-]+)*))?$`)
+
+// taggedImage is a tag of a repository with the digest and creation time
+// of the image it refers to.
+type taggedImage struct {
+	tag     string
+	digest  string
+	created time.Time
+	// blobs are the sizes of the configs and layers of the image by
+	// digest.
+	blobs map[string]uint64
+}
+
+// pruneSummary is the outcome of pruning a repository.
+type pruneSummary struct {
+	repo      reference.Reference
+	kept      int
+	deleted   int
+	reclaimed uint64
+}
+
+// repoPlan is the planned actions for the tags of a repository.
+type repoPlan struct {
+	repo    reference.Reference
+	client  *registry.Client
+	actions []pruneAction
+}
+
+// pruneAction is the planned action for a tag.
+type pruneAction struct {
+	taggedImage
+	// delete is set if the tag is to be deleted.
+	delete bool
+	// untag is set if the tag is to be deleted but other kept tags refer
+	// to the same digest, so only the tag can be deleted.
+	untag  bool
+	reason string
+	// done is set by executePlan if the tag was deleted.
+	done bool
+}
+
+// prune.Run executes the prune cli subcommand, deleting tags from
+// repositories according to retention rules.
+func (p *prune) Run(cfg *config) error {
+	ctx := cfg.context()
+	rulesFor, err := p.rulesFor()
+	if err != nil {
+		return err
+	}
+	repos, err := listRepos(ctx, cfg, p.Repositories, p.PageSize)
+	if err != nil {
+		return err
+	}
+
+	var targets []reference.Reference
+	var rules []*retentionRules
+	var clients []*registry.Client
+	for _, repo := range repos {
+		r := rulesFor(repo.Path)
+		if r == nil {
+			continue
+		}
+		client, err := cfg.clientFor(repo)
+		if err != nil {
+			return err
+		}
+		targets = append(targets, repo)
+		rules = append(rules, r)
+		clients = append(clients, client)
+	}
+	tags, err := listTags(ctx, clients, targets, p.PageSize, 0, p.Concurrency)
+	if err != nil {
+		return err
+	}
+
+	now := time.Now()
+	out := cfg.records()
+	var plans []repoPlan
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "REPOSITORY\tTAG\tDIGEST\tCREATED\tACTION")
+	for i, repo := range targets {
+		images, err := listTaggedImages(ctx, clients[i], repo.Path, tags[i], p.Concurrency)
+		if err != nil {
+			return err
+		}
+		rp := repoPlan{repo: repo, client: clients[i], actions: rules[i].plan(images, now)}
+		if out == nil {
+			printPlan(w, rp, cfg.Verbose)
+		}
+		plans = append(plans, rp)
+	}
+	if out == nil {
+		w.Flush()
+	}
+
+	var summaries []pruneSummary
+	failed := 0
+	for _, rp := range plans {
+		if !p.DryRun {
+			if err := executePlan(ctx, rp, p.Untag); err != nil {
+				failed++
+			}
+		}
+		summaries = append(summaries, summarize(rp, p.DryRun))
+	}
+
+	if out != nil {
+		if err := writeRecords(out, pruneRecords(plans)...); err != nil {
+			return err
+		}
+	} else {
+		fmt.Println()
+		printSummaries(os.Stdout, summaries, p.DryRun)
+	}
+	if p.DryRun {
+		if out == nil {
+			fmt.Println("\nDry run: nothing deleted. Use --no-dry-run to delete.")
+		}
+		return nil
+	}
+	if failed > 0 {
+		return fmt.Errorf("%d repositories not fully pruned", failed)
+	}
+	return nil
+}
+
+// rulesFor returns a function returning the retention rules for a
+// repository, from the policy file if set, otherwise from the rule flags.
+func (p *prune) rulesFor() (func(repo string) *retentionRules, error) {
+	if p.Policy == "" {
+		rules, err := p.rules()
+		if err != nil {
+			return nil, err
+		}
+		return func(string) *retentionRules { return rules }, nil
+	}
+	if p.KeepLast != 0 || p.OlderThan != "" || p.Include != "" || p.Exclude != "" || len(p.Protect) > 0 {
+		return nil, errors.New("--policy cannot be used with rule flags")
+	}
+	policy, err := loadPolicy(p.Policy)
+	if err != nil {
+		return nil, err
+	}
+	return policy.rulesFor, nil
+}
+
+func (p *prune) rules() (*retentionRules, error) {
+	rules := &retentionRules{keepLast: p.KeepLast, protect: map[string]bool{}}
+	if p.KeepLast < 0 {
+		return nil, fmt.Errorf("invalid --keep-last %d: must not be negative", p.KeepLast)
+	}
+	if p.OlderThan != "" {
+		d, err := parseAge(p.OlderThan)
+		if err != nil {
+			return nil, fmt.Errorf("invalid --older-than: %w", err)
+		}
+		rules.maxAge = d
+	}
+	if p.Include != "" {
+		re, err := regexp.Compile(p.Include)
+		if err != nil {
+			return nil, fmt.Errorf("invalid --include: %w", err)
+		}
+		rules.include = re
+	}
+	if p.Exclude != "" {
+		re, err := regexp.Compile(p.Exclude)
+		if err != nil {
+			return nil, fmt.Errorf("invalid --exclude: %w", err)
+		}
+		rules.exclude = append(rules.exclude, re)
+	}
+	for _, tag := range p.Protect {
+		rules.protect[tag] = true
+	}
+	if rules.keepLast == 0 && rules.maxAge == 0 && rules.include == nil {
+		return nil, errors.New("no retention rule: specify at least one of --keep-last, --older-than or --include")
+	}
+	return rules, nil
+}
+
+// parseAge parses a duration as for time.ParseDuration, additionally
+// accepting a whole number of days or weeks such as "30d" or "4w".
+func parseAge(s string) (time.Duration, error) {
+	units := map[string]time.Duration{"d": 24 * time.Hour, "w": 7 * 24 * time.Hour}
+	if unit, ok := units[s[len(s)-1:]]; ok {
+		if n, err := strconv.Atoi(s[:len(s)-1]); err == nil && n >= 0 {
+			return time.Duration(n) * unit, nil
+		}
+	}
+	d, err := time.ParseDuration(s)
+	if err != nil {
+		return 0, err
+	}
+	if d < 0 {
+		return 0, fmt.Errorf("negative duration %q", s)
+	}
+	return d, nil
+}
+
+// listTaggedImages returns tags of the named repository with the digest
+// and creation time of their images, fetching up to concurrency tags at
+// once. The creation time of an image index is that of its most recently
+// created image.
+func listTaggedImages(ctx context.Context, client *registry.Client, name string, tags []string, concurrency int) ([]taggedImage, error) {
+	result := make([]taggedImage, len(tags))
+	err := parallel(ctx, len(tags), concurrency, func(ctx context.Context, i int) error {
+		tag := tags[i]
+		resp, err := client.Manifest(ctx, name, tag)
+		if err != nil {
+			return err
+		}
+		images, err := client.Images(ctx, name, resp, nil)
+		if err != nil {
+			return err
+		}
+		ti := taggedImage{tag: tag, digest: resp.Digest, blobs: map[string]uint64{}}
+		if ti.digest == "" {
+			ti.digest = registry.SHA256Digest(resp.Raw)
+		}
+		for _, pi := range images {
+			ti.blobs[pi.Image.GetConfig().GetDigest()] = pi.Image.GetConfig().GetSize()
+			for _, layer := range pi.Image.Layers {
+				ti.blobs[layer.Digest] = layer.Size
+			}
+			config, _, err := client.ImageConfig(ctx, name, pi.Image)
+			if err != nil {
+				return fmt.Errorf("%s:%s: %w", name, tag, err)
+			}
+			if config.Created == nil {
+				continue
+			}
+			if created := config.Created.AsTime(); created.After(ti.created) {
+				ti.created = created
+			}
+		}
+		result[i] = ti
+		return nil
+	}, nil)
+	if err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// plan returns the action for each of images, ordered from most to least
+// recently created. Images with an unknown creation time are ordered last
+// and are never deleted by age.
+func (r *retentionRules) plan(images []taggedImage, now time.Time) []pruneAction {
+	sorted := append([]taggedImage(nil), images...)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		if !sorted[i].created.Equal(sorted[j].created) {
+			return sorted[i].created.After(sorted[j].created)
+		}
+		return sorted[i].tag < sorted[j].tag
+	})
+
+	actions := make([]pruneAction, len(sorted))
+	candidates := 0
+	for i, ti := range sorted {
+		a := pruneAction{taggedImage: ti}
+		switch {
+		case r.protect[ti.tag]:
+			a.reason = "protected"
+		case r.isExcluded(ti.tag):
+			a.reason = "excluded"
+		case r.keepSemver && semverRE.MatchString(ti.tag):
+			a.reason = "semver"
+		case r.include != nil && !r.include.MatchString(ti.tag):
+			a.reason = "not included"
+		case candidates < r.keepLast:
+			candidates++
+			a.reason = fmt.Sprintf("last %d", r.keepLast)
+		case r.maxAge > 0 && ti.created.IsZero():
+			candidates++
+			a.reason = "unknown age"
+		case r.maxAge > 0 && now.Sub(ti.created) < r.maxAge:
+			candidates++
+			a.reason = "too new"
+		default:
+			candidates++
+			a.delete = true
+		}
+		actions[i] = a
+	}
+
+	// Deleting a digest deletes all its tags, so tags sharing a digest
+	// with a kept tag can only be untagged.
+	kept := map[string]bool{}
+	for _, a := range actions {
+		if !a.delete {
+			kept[a.digest] = true
+		}
+	}
+	for i := range actions {
+		if actions[i].delete && kept[actions[i].digest] {
+			actions[i].untag = true
+		}
+	}
+	return actions
+}
+
+func (r *retentionRules) isExcluded(tag string) bool {
+	for _, re := range r.exclude {
+		if re.MatchString(tag) {
+			return true
+		}
+	}
+	return false
+}
+
+func printPlan(w io.Writer, rp repoPlan, verbose bool) {
+	for _, a := range rp.actions {
+		if !a.delete && !verbose {
+			continue
+		}
+		action := a.action()
+		if !a.delete {
+			action += " (" + a.reason + ")"
+		}
+		created := "unknown"
+		if !a.created.IsZero() {
+			created = humanize.Time(a.created)
+		}
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\n", rp.repo.Name(), a.tag, shortDigest(a.digest), created, action)
+	}
+}
+
+// pruneRecords returns a record of each action of plans.
+func pruneRecords(plans []repoPlan) []proto.Message {
+	var records []proto.Message
+	for _, rp := range plans {
+		for _, a := range rp.actions {
+			r := &pb.PruneRecord{
+				Repository: rp.repo.Name(),
+				Tag:        a.tag,
+				Digest:     a.digest,
+				Action:     a.action(),
+				Reason:     a.reason,
+				Deleted:    a.done,
+			}
+			if !a.created.IsZero() {
+				r.Created = timestamppb.New(a.created)
+			}
+			records = append(records, r)
+		}
+	}
+	return records
+}
+
+// action returns "keep", "delete" or "untag".
+func (a pruneAction) action() string {
+	switch {
+	case a.untag:
+		return "untag"
+	case a.delete:
+		return "delete"
+	}
+	return "keep"
+}
+
+// summarize returns the number of tags kept and deleted by rp and the total
+// size of the blobs referenced only by the deleted images. If dryRun is
+// set, these are the tags planned for deletion, otherwise those executePlan
+// deleted. The space is reclaimed when the registry garbage collects
+// unreferenced blobs, unless the blobs are also used in other repositories.
+func summarize(rp repoPlan, dryRun bool) pruneSummary {
+	deleted := func(a pruneAction) bool {
+		if dryRun {
+			return a.delete
+		}
+		return a.done
+	}
+	summary := pruneSummary{repo: rp.repo}
+	kept := map[string]bool{}
+	for _, a := range rp.actions {
+		if deleted(a) {
+			summary.deleted++
+			continue
+		}
+		summary.kept++
+		for digest := range a.blobs {
+			kept[digest] = true
+		}
+	}
+	counted := map[string]bool{}
+	for _, a := range rp.actions {
+		if !deleted(a) || a.untag {
+			continue
+		}
+		for digest, size := range a.blobs {
+			if !kept[digest] && !counted[digest] {
+				counted[digest] = true
+				summary.reclaimed += size
+			}
+		}
+	}
+	return summary
+}
+
+func printSummaries(out io.Writer, summaries []pruneSummary, dryRun bool) {
+	w := tabwriter.NewWriter(out, 0, 0, 2, ' ', 0)
+	deleted, reclaimed := "DELETED", "RECLAIMED"
+	if dryRun {
+		deleted, reclaimed = "TO DELETE", "TO RECLAIM"
+	}
+	fmt.Fprintf(w, "REPOSITORY\tKEPT\t%s\t%s\n", deleted, reclaimed)
+	var total pruneSummary
+	for _, s := range summaries {
+		fmt.Fprintf(w, "%s\t%d\t%d\t%s\n", s.repo.Name(), s.kept, s.deleted, humanize.Bytes(s.reclaimed))
+		total.kept += s.kept
+		total.deleted += s.deleted
+		total.reclaimed += s.reclaimed
+	}
+	fmt.Fprintf(w, "TOTAL\t%d\t%d\t%s\n", total.kept, total.deleted, humanize.Bytes(total.reclaimed))
+	w.Flush()
+}
+
+// executePlan deletes the tags of rp.repo planned for deletion, setting
+// done on the actions of the tags deleted. Digests are deleted once for all
+// their tags.
+// Tags to untag are not deleted unless untagOK is set, as registries that
+// do not support deleting tags may delete the manifest and so the kept
+// tags too. They are deleted individually and only counted if the kept tags
+// sharing their digest remain.
+func executePlan(ctx context.Context, rp repoPlan, untagOK bool) error {
+	client, repo := rp.client, rp.repo
+	var errs []string
+	deleted := map[string]bool{}
+	for i, a := range rp.actions {
+		if !a.delete {
+			continue
+		}
+		if ctx.Err() != nil {
+			errs = append(errs, fmt.Sprintf("%s:%s: %v", repo.Name(), a.tag, ctx.Err()))
+			continue
+		}
+		if a.untag {
+			err := errors.New("shares digest with a kept tag (use --untag to delete only the tag if the registry supports it)")
+			if untagOK {
+				err = untag(ctx, client, repo.Path, a.tag, rp.keptTags(a.digest))
+			}
+			if err != nil {
+				errs = append(errs, fmt.Sprintf("%s:%s: %v", repo.Name(), a.tag, err))
+				continue
+			}
+			rp.actions[i].done = true
+			continue
+		}
+		if !deleted[a.digest] {
+			if err := client.Delete(ctx, repo.Path, a.digest); err != nil && status.Code(err) != codes.NotFound {
+				errs = append(errs, fmt.Sprintf("%s:%s: %v", repo.Name(), a.tag, err))
+				continue
+			}
+			deleted[a.digest] = true
+		}
+		rp.actions[i].done = true
+	}
+	for _, e := range errs {
+		fmt.Fprintln(os.Stderr, "Couldn't remove "+e)
+	}
+	if len(errs) > 0 {
+		return fmt.Errorf("%d tag(s) not removed", len(errs))
+	}
+	return nil
+}
+
+// keptTags returns the tags of rp that are kept and refer to digest.
+func (rp repoPlan) keptTags(digest string) []string {
+	var tags []string
+	for _, a := range rp.actions {
+		if !a.delete && a.digest == digest {
+			tags = append(tags, a.tag)
+		}
+	}
+	return tags
+}
+
+// shortDigest returns the first 12 hex digits of a digest, as docker shows
+// image IDs.
+func shortDigest(digest string) string {
+	_, hex := registry.SplitDigest(digest)
+	if len(hex) > 12 {
+		hex = hex[:12]
+	}
+	return hex
+}
@@ -0,0 +1,166 @@
+package main
+
+import (
+	"regexp"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestParseAge(t *testing.T) {
+	tests := map[string]time.Duration{
+		"30d":   30 * 24 * time.Hour,
+		"4w":    4 * 7 * 24 * time.Hour,
+		"0d":    0,
+		"720h":  720 * time.Hour,
+		"1h30m": 90 * time.Minute,
+	}
+	for s, want := range tests {
+		got, err := parseAge(s)
+		if err != nil || got != want {
+			t.Errorf("parseAge(%q) = %v, %v, want %v", s, got, err, want)
+		}
+	}
+	for _, s := range []string{"d", "-1d", "1.5d", "-1h", "3x", "30"} {
+		if got, err := parseAge(s); err == nil {
+			t.Errorf("parseAge(%q) = %v, want error", s, got)
+		}
+	}
+}
+
+func TestGlobRegexp(t *testing.T) {
+	tests := []struct {
+		glob  string
+		match []string
+		skip  []string
+	}{
+		{glob: "app", match: []string{"app"}, skip: []string{"ci/app", "app2"}},
+		{glob: "ci/*", match: []string{"ci/app", "ci/"}, skip: []string{"ci", "ci/a/b", "team/ci/app"}},
+		{glob: "team-a/**", match: []string{"team-a/x", "team-a/x/y/z"}, skip: []string{"team-a", "team-ab/x"}},
+		{glob: "**/cache", match: []string{"a/cache", "a/b/cache"}, skip: []string{"cache", "a/cache2"}},
+		{glob: "app-?", match: []string{"app-1", "app-a"}, skip: []string{"app-", "app-12", "app-/"}},
+		{glob: "a.b+c", match: []string{"a.b+c"}, skip: []string{"axb+c", "a.bbc"}},
+	}
+	for _, tt := range tests {
+		re := globRegexp(tt.glob)
+		for _, s := range tt.match {
+			if !re.MatchString(s) {
+				t.Errorf("glob %q does not match %q", tt.glob, s)
+			}
+		}
+		for _, s := range tt.skip {
+			if re.MatchString(s) {
+				t.Errorf("glob %q matches %q", tt.glob, s)
+			}
+		}
+	}
+}
+
+func TestPlan(t *testing.T) {
+	now := time.Date(2024, 6, 1, 0, 0, 0, 0, time.UTC)
+	// image returns a tag of the image with the given digest created days
+	// ago, or with an unknown creation time if days is negative.
+	image := func(tag, digest string, days int) taggedImage {
+		ti := taggedImage{tag: tag, digest: digest}
+		if days >= 0 {
+			ti.created = now.Add(-time.Duration(days) * 24 * time.Hour)
+		}
+		return ti
+	}
+	images := []taggedImage{
+		image("v1.0.0", "sha256:1", 40),
+		image("v1.1.0", "sha256:2", 30),
+		image("build-1", "sha256:3", 20),
+		image("build-2", "sha256:4", 10),
+		image("build-3", "sha256:5", 1),
+		image("latest", "sha256:5", 1),
+		image("unknown", "sha256:6", -1),
+	}
+	tests := []struct {
+		name  string
+		rules retentionRules
+		// want is the action and reason for each tag, from the most to
+		// the least recently created.
+		want []string
+	}{
+		{
+			name:  "keep last",
+			rules: retentionRules{keepLast: 3},
+			want: []string{
+				"build-3 keep (last 3)", "latest keep (last 3)", "build-2 keep (last 3)",
+				"build-1 delete", "v1.1.0 delete", "v1.0.0 delete", "unknown delete",
+			},
+		},
+		{
+			name:  "max age",
+			rules: retentionRules{maxAge: 15 * 24 * time.Hour},
+			want: []string{
+				"build-3 keep (too new)", "latest keep (too new)", "build-2 keep (too new)",
+				"build-1 delete", "v1.1.0 delete", "v1.0.0 delete", "unknown keep (unknown age)",
+			},
+		},
+		{
+			name:  "keep last within max age",
+			rules: retentionRules{keepLast: 4, maxAge: 25 * 24 * time.Hour},
+			want: []string{
+				"build-3 keep (last 4)", "latest keep (last 4)", "build-2 keep (last 4)",
+				"build-1 keep (last 4)", "v1.1.0 delete", "v1.0.0 delete", "unknown keep (unknown age)",
+			},
+		},
+		{
+			name: "protect and exclude before keep last",
+			rules: retentionRules{
+				keepLast: 1,
+				protect:  map[string]bool{"latest": true},
+				exclude:  []*regexp.Regexp{regexp.MustCompile(`^v1\.1`)},
+			},
+			want: []string{
+				"build-3 keep (last 1)", "latest keep (protected)", "build-2 delete",
+				"build-1 delete", "v1.1.0 keep (excluded)", "v1.0.0 delete", "unknown delete",
+			},
+		},
+		{
+			name:  "keep semver",
+			rules: retentionRules{keepLast: 1, keepSemver: true},
+			want: []string{
+				"build-3 keep (last 1)", "latest untag", "build-2 delete",
+				"build-1 delete", "v1.1.0 keep (semver)", "v1.0.0 keep (semver)", "unknown delete",
+			},
+		},
+		{
+			name:  "include",
+			rules: retentionRules{keepLast: 1, include: regexp.MustCompile(`^build-`)},
+			want: []string{
+				"build-3 keep (last 1)", "latest keep (not included)", "build-2 delete",
+				"build-1 delete", "v1.1.0 keep (not included)", "v1.0.0 keep (not included)", "unknown keep (not included)",
+			},
+		},
+		{
+			name: "protected over excluded",
+			rules: retentionRules{
+				include: regexp.MustCompile(`.`),
+				protect: map[string]bool{"build-1": true},
+				exclude: []*regexp.Regexp{regexp.MustCompile(`^build-`)},
+			},
+			want: []string{
+				"build-3 keep (excluded)", "latest untag", "build-2 keep (excluded)",
+				"build-1 keep (protected)", "v1.1.0 delete", "v1.0.0 delete", "unknown delete",
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			actions := tt.rules.plan(images, now)
+			got := make([]string, len(actions))
+			for i, a := range actions {
+				got[i] = a.tag + " " + a.action()
+				if a.reason != "" {
+					got[i] += " (" + a.reason + ")"
+				}
+			}
+			if strings.Join(got, "\n") != strings.Join(tt.want, "\n") {
+				t.Errorf("got:\n%s\nwant:\n%s", strings.Join(got, "\n"), strings.Join(tt.want, "\n"))
+			}
+		})
+	}
+}
@@ -0,0 +1,146 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"io"
+
+	"foxygo.at/dreg/pb"
+	"foxygo.at/dreg/registry"
+)
+
+type push struct {
+	Source    string `arg:"" type:"path" help:"OCI image layout directory or docker save tarball"`
+	Image     string `arg:"" help:"Image to push to, as [registry/]name[:tag]"`
+	ChunkSize int    `default:"16777216" help:"Size of blob upload chunks in bytes (0 to upload blobs in one request)"`
+}
+
+// push.Run executes the push cli subcommand, uploading an image from an
+// OCI image layout or docker save tarball to the registry.
+func (p *push) Run(cfg *config) error {
+	ctx := cfg.context()
+	src, err := openImageSource(p.Source)
+	if err != nil {
+		return err
+	}
+	defer src.Close()
+
+	client, ref, err := cfg.resolveImage(p.Image)
+	if err != nil {
+		return err
+	}
+	desc, err := src.findManifest(ref)
+	if err != nil {
+		return err
+	}
+	pusher := &imagePusher{client: client, src: layoutSource{src}, name: ref.Path, chunkSize: p.ChunkSize}
+	digest, err := pusher.pushManifest(ctx, desc, ref.TagOrDigest())
+	if err != nil {
+		return err
+	}
+	if out := cfg.records(); out != nil {
+		return writeRecords(out, &pb.ImageRecord{Repository: ref.Name(), Tag: ref.Tag, Digest: digest, MediaType: desc.MediaType})
+	}
+	fmt.Printf("%s@%s\n", ref.Name(), digest)
+	return nil
+}
+
+// imagePusher pushes images from a blob source to a repository.
+type imagePusher struct {
+	client *registry.Client
+	src    blobSource
+	name   string
+	// from is a repository in the same registry to mount blobs from.
+	from      string
+	chunkSize int
+}
+
+// blobSource reads the manifests and blobs of images being pushed.
+type blobSource interface {
+	// readManifest reads the manifest with the given digest, verifying
+	// its contents match the digest.
+	readManifest(ctx context.Context, digest string) ([]byte, error)
+	openBlob(ctx context.Context, digest string) (io.ReadCloser, error)
+}
+
+// layoutSource is a blobSource for an OCI image layout or docker save
+// tarball.
+type layoutSource struct {
+	*imageSource
+}
+
+func (s layoutSource) readManifest(_ context.Context, digest string) ([]byte, error) {
+	return s.readBlob(digest)
+}
+
+func (s layoutSource) openBlob(_ context.Context, digest string) (io.ReadCloser, error) {
+	return s.open(digest)
+}
+
+// pushManifest pushes the manifest described by desc with the given
+// reference, after pushing the blobs it references or, for an image index,
+// the manifests it references. The manifest is pushed as is so its digest
+// does not change.
+func (ip *imagePusher) pushManifest(ctx context.Context, desc *pb.Descriptor, reference string) (string, error) {
+	raw, err := ip.src.readManifest(ctx, desc.Digest)
+	if err != nil {
+		return "", err
+	}
+	return ip.pushRawManifest(ctx, desc, raw, reference)
+}
+
+// pushRawManifest pushes the manifest described by desc with contents raw,
+// already read from the source, as pushManifest does.
+func (ip *imagePusher) pushRawManifest(ctx context.Context, desc *pb.Descriptor, raw []byte, reference string) (string, error) {
+	m, err := registry.ParseManifest(desc.MediaType, raw)
+	if err != nil {
+		return "", fmt.Errorf("%s: %w", desc.Digest, err)
+	}
+	mediaType := desc.MediaType
+	if !registry.IsManifestMediaType(mediaType) {
+		mediaType = registry.ManifestMediaType(m)
+	}
+	switch m := m.Manifest.(type) {
+	case *pb.Manifest_Image:
+		if err := ip.pushBlobs(ctx, m.Image); err != nil {
+			return "", err
+		}
+	case *pb.Manifest_Index:
+		for _, child := range m.Index.Manifests {
+			if _, err := ip.pushManifest(ctx, child, child.Digest); err != nil {
+				return "", err
+			}
+		}
+	}
+
+	req := &pb.PutManifestRequest{Name: ip.name, Reference: reference, MediaType: mediaType, Raw: raw}
+	resp, err := ip.client.PutManifest(ctx, req)
+	if err != nil {
+		return "", err
+	}
+	if resp.Digest != "" && resp.Digest != desc.Digest {
+		return "", fmt.Errorf("%s: registry reported manifest digest %s", desc.Digest, resp.Digest)
+	}
+	return desc.Digest, nil
+}
+
+// pushBlobs pushes the config and layers of image. Non-distributable
+// layers that are not in the source are skipped as they are fetched from
+// their URLs instead.
+func (ip *imagePusher) pushBlobs(ctx context.Context, image *pb.ImageManifest) error {
+	blobs := append([]*pb.Descriptor{image.Config}, image.Layers...)
+	for _, desc := range blobs {
+		open := func() (io.ReadCloser, error) { return ip.src.openBlob(ctx, desc.Digest) }
+		if len(desc.Urls) > 0 {
+			r, err := open()
+			if err != nil {
+				continue
+			}
+			r.Close()
+		}
+		if err := ip.client.PushBlob(ctx, ip.name, desc, ip.from, open, ip.chunkSize); err != nil {
+			return fmt.Errorf("cannot push blob %s: %w", desc.Digest, err)
+		}
+	}
+	return nil
+}
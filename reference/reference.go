@@ -0,0 +1,163 @@
+// Package reference parses docker and OCI image references such as
+// "localhost:5000/foo/bar:v1" or "alpine@sha256:...".
+//
+// The grammar follows github.com/distribution/reference:
+//
+//	reference       := name [ ":" tag ] [ "@" digest ]
+//	name            := [domain '/'] path-component ['/' path-component]*
+//	domain          := host [':' port-number]
+//	host            := domain-name | '[' IPv6address ']'
+//	domain-name     := domain-component ['.' domain-component]*
+//	domain-component := /([a-zA-Z0-9]|[a-zA-Z0-9][a-zA-Z0-9-]*[a-zA-Z0-9])/
+//	port-number     := /[0-9]+/
+//	path-component  := alpha-numeric [separator alpha-numeric]*
+//	alpha-numeric   := /[a-z0-9]+/
+//	separator       := /[_.]|__|[-]*/
+//	tag             := /[\w][\w.-]{0,127}/
+//	digest          := algorithm ":" encoded
+//	algorithm       := /[A-Za-z][A-Za-z0-9]*([-_+.][A-Za-z][A-Za-z0-9]*)*/
+//	encoded         := /[a-fA-F0-9]{32,}/
+//
+// The first component of a name is the domain only if it contains a "." or
+// ":", is "localhost" or contains upper case letters.
+package reference
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// Docker Hub names.
+const (
+	// DockerHubDomain is the domain of Docker Hub references.
+	DockerHubDomain = "docker.io"
+	// DockerHubRegistry is the host serving the registry API for Docker
+	// Hub.
+	DockerHubRegistry = "registry-1.docker.io"
+	// legacyDockerHubDomain is normalised to DockerHubDomain.
+	legacyDockerHubDomain = "index.docker.io"
+	// officialRepoPrefix is prepended to single component Docker Hub
+	// paths.
+	officialRepoPrefix = "library/"
+)
+
+// Maximum length of a name, including the domain.
+const maxNameLength = 255
+
+// Errors returned by Parse, wrapped with the reference being parsed.
+var (
+	ErrInvalidFormat = errors.New("invalid reference format")
+	ErrNameNotLower  = errors.New("repository name must be lowercase")
+	ErrNameTooLong   = fmt.Errorf("repository name must not be more than %d characters", maxNameLength)
+)
+
+const (
+	domainComponent = `(?:[a-zA-Z0-9]|[a-zA-Z0-9][a-zA-Z0-9-]*[a-zA-Z0-9])`
+	domain          = `(?:` + domainComponent + `(?:\.` + domainComponent + `)*|\[[a-fA-F0-9:]+\])(?::[0-9]+)?`
+	pathComponent   = `[a-z0-9]+(?:(?:[._]|__|[-]*)[a-z0-9]+)*`
+	name            = `(?:` + domain + `/)?` + pathComponent + `(?:/` + pathComponent + `)*`
+	tag             = `[\w][\w.-]{0,127}`
+	digest          = `[A-Za-z][A-Za-z0-9]*(?:[-_+.][A-Za-z][A-Za-z0-9]*)*:[a-fA-F0-9]{32,}`
+)
+
+var referenceRE = regexp.MustCompile(`^(` + name + `)(?::(` + tag + `))?(?:@(` + digest + `))?$`)
+
+// Reference is a parsed image reference.
+type Reference struct {
+	// Domain is the registry host and optional port. It is empty if the
+	// reference does not name a registry.
+	Domain string
+	// Path is the repository path within the registry.
+	Path   string
+	Tag    string
+	Digest string
+}
+
+// Parse parses s as an image reference. Domain is left empty if s does not
+// name a registry, and Docker Hub references are not normalised.
+func Parse(s string) (Reference, error) {
+	m := referenceRE.FindStringSubmatch(s)
+	if m == nil {
+		if referenceRE.MatchString(strings.ToLower(s)) {
+			return Reference{}, fmt.Errorf("%q: %w", s, ErrNameNotLower)
+		}
+		return Reference{}, fmt.Errorf("%q: %w", s, ErrInvalidFormat)
+	}
+	if len(m[1]) > maxNameLength {
+		return Reference{}, fmt.Errorf("%q: %w", s, ErrNameTooLong)
+	}
+	r := Reference{Path: m[1], Tag: m[2], Digest: m[3]}
+	if i := strings.IndexByte(r.Path, '/'); i >= 0 && isDomain(r.Path[:i]) {
+		r.Domain, r.Path = r.Path[:i], r.Path[i+1:]
+	}
+	return r, nil
+}
+
+// ParseNormalized parses s as an image reference, normalising references
+// without a domain to Docker Hub references as docker does.
+func ParseNormalized(s string) (Reference, error) {
+	r, err := Parse(s)
+	if err != nil {
+		return Reference{}, err
+	}
+	return r.Normalized(), nil
+}
+
+// Normalized returns r with Docker Hub normalisation applied: an empty or
+// legacy domain becomes "docker.io", and single component Docker Hub paths
+// are prefixed with "library/".
+func (r Reference) Normalized() Reference {
+	if r.Domain == "" || r.Domain == legacyDockerHubDomain {
+		r.Domain = DockerHubDomain
+	}
+	if r.Domain == DockerHubDomain && !strings.Contains(r.Path, "/") {
+		r.Path = officialRepoPrefix + r.Path
+	}
+	return r
+}
+
+// Name returns the domain and path of r.
+func (r Reference) Name() string {
+	if r.Domain == "" {
+		return r.Path
+	}
+	return r.Domain + "/" + r.Path
+}
+
+// TagOrDigest returns the digest of r if it has one, otherwise its tag,
+// defaulting to "latest".
+func (r Reference) TagOrDigest() string {
+	if r.Digest != "" {
+		return r.Digest
+	}
+	if r.Tag != "" {
+		return r.Tag
+	}
+	return "latest"
+}
+
+// Registry returns the host serving the registry API for r, which differs
+// from the domain for Docker Hub.
+func (r Reference) Registry() string {
+	if r.Domain == DockerHubDomain || r.Domain == legacyDockerHubDomain {
+		return DockerHubRegistry
+	}
+	return r.Domain
+}
+
+func (r Reference) String() string {
+	s := r.Name()
+	if r.Tag != "" {
+		s += ":" + r.Tag
+	}
+	if r.Digest != "" {
+		s += "@" + r.Digest
+	}
+	return s
+}
+
+func isDomain(s string) bool {
+	return strings.ContainsAny(s, ".:") || s == "localhost" || strings.ToLower(s) != s
+}
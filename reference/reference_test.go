@@ -0,0 +1,126 @@
+package reference
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+const testDigest = "sha256:0123456789abcdef0123456789abcdef0123456789abcdef0123456789abcdef"
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		in   string
+		want Reference
+	}{
+		{"foo", Reference{Path: "foo"}},
+		{"foo/bar:v1", Reference{Path: "foo/bar", Tag: "v1"}},
+		{"localhost/foo", Reference{Domain: "localhost", Path: "foo"}},
+		{"localhost:5000/foo:bar", Reference{Domain: "localhost:5000", Path: "foo", Tag: "bar"}},
+		{"example.com/a/b/c", Reference{Domain: "example.com", Path: "a/b/c"}},
+		{"registry:5000/name@" + testDigest, Reference{Domain: "registry:5000", Path: "name", Digest: testDigest}},
+		{"foo/bar:v1@" + testDigest, Reference{Path: "foo/bar", Tag: "v1", Digest: testDigest}},
+		{"[::1]:5000/foo:bar", Reference{Domain: "[::1]:5000", Path: "foo", Tag: "bar"}},
+		{"[2001:db8::1]/foo", Reference{Domain: "[2001:db8::1]", Path: "foo"}},
+		// Upper case first components are domains.
+		{"Example/foo", Reference{Domain: "Example", Path: "foo"}},
+		{"a_b__c-d--e/f.g", Reference{Path: "a_b__c-d--e/f.g"}},
+		{"foo:" + strings.Repeat("t", 128), Reference{Path: "foo", Tag: strings.Repeat("t", 128)}},
+		{"foo:_V1.0-rc", Reference{Path: "foo", Tag: "_V1.0-rc"}},
+	}
+	for _, tt := range tests {
+		got, err := Parse(tt.in)
+		if err != nil {
+			t.Errorf("Parse(%q): %v", tt.in, err)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("Parse(%q) = %+v, want %+v", tt.in, got, tt.want)
+		}
+		if s := got.String(); s != tt.in {
+			t.Errorf("Parse(%q).String() = %q", tt.in, s)
+		}
+	}
+}
+
+func TestParseErrors(t *testing.T) {
+	tests := []struct {
+		in   string
+		want error
+	}{
+		{"", ErrInvalidFormat},
+		{"foo:", ErrInvalidFormat},
+		{":v1", ErrInvalidFormat},
+		{"foo//bar", ErrInvalidFormat},
+		{"-foo", ErrInvalidFormat},
+		{"foo-", ErrInvalidFormat},
+		{"foo bar", ErrInvalidFormat},
+		{"foo:-v1", ErrInvalidFormat},
+		{"foo:" + strings.Repeat("t", 129), ErrInvalidFormat},
+		{"foo@sha256:abc", ErrInvalidFormat},
+		{"foo@" + testDigest + ":v1", ErrInvalidFormat},
+		{"example.com:port/foo", ErrInvalidFormat},
+		{"FOO", ErrNameNotLower},
+		{"foo/Bar", ErrNameNotLower},
+		{"example.com/Foo:v1", ErrNameNotLower},
+		{strings.Repeat("a", 256), ErrNameTooLong},
+		{"example.com/" + strings.Repeat("a", 244), ErrNameTooLong},
+	}
+	for _, tt := range tests {
+		if _, err := Parse(tt.in); !errors.Is(err, tt.want) {
+			t.Errorf("Parse(%q) error = %v, want %v", tt.in, err, tt.want)
+		}
+	}
+}
+
+func TestParseNormalized(t *testing.T) {
+	tests := []struct {
+		in       string
+		name     string
+		registry string
+	}{
+		{"alpine", "docker.io/library/alpine", DockerHubRegistry},
+		{"alpine:3", "docker.io/library/alpine", DockerHubRegistry},
+		{"user/app", "docker.io/user/app", DockerHubRegistry},
+		{"docker.io/alpine", "docker.io/library/alpine", DockerHubRegistry},
+		{"index.docker.io/alpine", "docker.io/library/alpine", DockerHubRegistry},
+		{"index.docker.io/user/app", "docker.io/user/app", DockerHubRegistry},
+		{"localhost/alpine", "localhost/alpine", "localhost"},
+		{"localhost:5000/foo", "localhost:5000/foo", "localhost:5000"},
+		{"ghcr.io/org/app", "ghcr.io/org/app", "ghcr.io"},
+	}
+	for _, tt := range tests {
+		r, err := ParseNormalized(tt.in)
+		if err != nil {
+			t.Errorf("ParseNormalized(%q): %v", tt.in, err)
+			continue
+		}
+		if got := r.Name(); got != tt.name {
+			t.Errorf("ParseNormalized(%q).Name() = %q, want %q", tt.in, got, tt.name)
+		}
+		if got := r.Registry(); got != tt.registry {
+			t.Errorf("ParseNormalized(%q).Registry() = %q, want %q", tt.in, got, tt.registry)
+		}
+	}
+	// The legacy Docker Hub domain is served by the Docker Hub registry
+	// without normalisation too.
+	if got := (Reference{Domain: "index.docker.io", Path: "library/alpine"}).Registry(); got != DockerHubRegistry {
+		t.Errorf("Registry() of index.docker.io = %q, want %q", got, DockerHubRegistry)
+	}
+}
+
+func TestTagOrDigest(t *testing.T) {
+	tests := []struct {
+		r    Reference
+		want string
+	}{
+		{Reference{Path: "foo"}, "latest"},
+		{Reference{Path: "foo", Tag: "v1"}, "v1"},
+		{Reference{Path: "foo", Tag: "v1", Digest: testDigest}, testDigest},
+	}
+	for _, tt := range tests {
+		if got := tt.r.TagOrDigest(); got != tt.want {
+			t.Errorf("%v.TagOrDigest() = %q, want %q", tt.r, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,356 @@
+package registry
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+)
+
+// authTransport is an http.RoundTripper that authenticates requests to a
+// registry. It sends basic auth credentials where it is safe to do so and
+// responds to a "WWW-Authenticate: Bearer" challenge by fetching a token
+// from the challenge realm, caching it by scope and retrying the request.
+// Identity tokens are exchanged for bearer tokens using OAuth2.
+//
+// https://docs.docker.com/registry/spec/auth/token/
+// https://docs.docker.com/registry/spec/auth/oauth/
+type authTransport struct {
+	base http.RoundTripper
+	// host is the registry host. Requests to other hosts, such as blob
+	// storage redirects, are not authenticated.
+	host string
+	// getCreds returns the credentials for the registry. It is called at
+	// most once, when credentials are first needed, as it may run a
+	// credential helper.
+	getCreds  func() (credentials, error)
+	credsOnce sync.Once
+	creds     credentials
+	credsErr  error
+
+	mu sync.Mutex
+	// tokens holds bearer tokens keyed by challenge scope.
+	tokens map[string]token
+	// scopes maps a request's resource (see resourceKey) to the scope of
+	// the last challenge received for it, so subsequent requests for the
+	// same resource can send a cached token without a 401 round trip.
+	scopes map[string]string
+}
+
+type token struct {
+	value   string
+	expires time.Time
+}
+
+// challenge is a parsed WWW-Authenticate header.
+type challenge struct {
+	scheme string
+	params map[string]string
+}
+
+// tokenResponse is the body returned by a token server.
+// https://docs.docker.com/registry/spec/auth/token/#token-response-fields
+type tokenResponse struct {
+	Token       string    `json:"token"`
+	AccessToken string    `json:"access_token"`
+	ExpiresIn   int       `json:"expires_in"`
+	IssuedAt    time.Time `json:"issued_at"`
+}
+
+var errTokenDenied = errors.New("token request denied")
+
+// Tokens without an expiry are valid for at least 60 seconds as per spec.
+const defaultTokenExpiry = 60 * time.Second
+
+// OAuth2 client ID sent with identity token requests.
+const oauthClientID = "dreg"
+
+func newAuthTransport(host string, getCreds func() (credentials, error)) *authTransport {
+	return &authTransport{
+		base:     http.DefaultTransport,
+		host:     host,
+		getCreds: getCreds,
+		tokens:   map[string]token{},
+		scopes:   map[string]string{},
+	}
+}
+
+func (t *authTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if req.URL.Host != t.host {
+		return t.base.RoundTrip(req)
+	}
+	key := resourceKey(req)
+	r := req.Clone(req.Context())
+	if tok, ok := t.cachedToken(key); ok {
+		r.Header.Set("Authorization", "Bearer "+tok)
+	} else if isSecure(r.URL) {
+		creds, err := t.credentials()
+		if err != nil {
+			return nil, err
+		}
+		if auth := creds.basicAuth(); auth != "" {
+			r.Header.Set("Authorization", "Basic "+auth)
+		}
+	}
+	resp, err := t.base.RoundTrip(r)
+	if err != nil || resp.StatusCode != http.StatusUnauthorized {
+		return resp, err
+	}
+
+	c, ok := parseChallenge(resp.Header.Get("WWW-Authenticate"))
+	if !ok || c.scheme != "bearer" {
+		return resp, nil
+	}
+	if req.Body != nil && req.GetBody == nil {
+		// Cannot replay the request body. Return the 401 as is.
+		return resp, nil
+	}
+	tok, err := t.fetchToken(req, c)
+	if errors.Is(err, errTokenDenied) {
+		// Return the registry's 401 so it is reported as unauthenticated.
+		return resp, nil
+	}
+	if err != nil {
+		resp.Body.Close()
+		return nil, err
+	}
+	scope := c.params["scope"]
+	t.mu.Lock()
+	t.tokens[scope] = tok
+	t.scopes[key] = scope
+	t.mu.Unlock()
+
+	resp.Body.Close()
+	r = req.Clone(req.Context())
+	if req.GetBody != nil {
+		if r.Body, err = req.GetBody(); err != nil {
+			return nil, err
+		}
+	}
+	r.Header.Set("Authorization", "Bearer "+tok.value)
+	return t.base.RoundTrip(r)
+}
+
+func (t *authTransport) credentials() (credentials, error) {
+	t.credsOnce.Do(func() {
+		t.creds, t.credsErr = t.getCreds()
+	})
+	return t.creds, t.credsErr
+}
+
+func (t *authTransport) cachedToken(key string) (string, bool) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	scope, ok := t.scopes[key]
+	if !ok {
+		return "", false
+	}
+	tok, ok := t.tokens[scope]
+	if !ok || time.Now().After(tok.expires) {
+		return "", false
+	}
+	return tok.value, true
+}
+
+// fetchToken requests a bearer token from the realm of the challenge c.
+// An identity token is exchanged for the bearer token if available,
+// otherwise basic auth credentials are used if available.
+func (t *authTransport) fetchToken(req *http.Request, c challenge) (token, error) {
+	realm, err := url.Parse(c.params["realm"])
+	if err != nil || realm.Host == "" {
+		return token{}, fmt.Errorf("invalid bearer realm %q", c.params["realm"])
+	}
+	var creds credentials
+	if isSecure(realm) {
+		if creds, err = t.credentials(); err != nil {
+			return token{}, err
+		}
+	}
+	var tr *http.Request
+	if creds.identityToken != "" {
+		tr, err = oauthTokenRequest(req, realm, c, creds.identityToken)
+	} else {
+		tr, err = basicTokenRequest(req, realm, c, creds.basicAuth())
+	}
+	if err != nil {
+		return token{}, err
+	}
+	resp, err := t.base.RoundTrip(tr)
+	if err != nil {
+		return token{}, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return token{}, fmt.Errorf("%w: %s: %s", errTokenDenied, realm.Host, resp.Status)
+	}
+	return decodeToken(resp)
+}
+
+// basicTokenRequest returns a GET request for a token, authenticated with
+// the base64 encoded basic auth credentials if not empty.
+// https://docs.docker.com/registry/spec/auth/token/#requesting-a-token
+func basicTokenRequest(req *http.Request, realm *url.URL, c challenge, auth string) (*http.Request, error) {
+	u := *realm
+	q := u.Query()
+	if service, ok := c.params["service"]; ok {
+		q.Set("service", service)
+	}
+	for _, scope := range strings.Fields(c.params["scope"]) {
+		q.Add("scope", scope)
+	}
+	u.RawQuery = q.Encode()
+	tr, err := http.NewRequestWithContext(req.Context(), http.MethodGet, u.String(), nil)
+	if err != nil {
+		return nil, err
+	}
+	if auth != "" {
+		tr.Header.Set("Authorization", "Basic "+auth)
+	}
+	return tr, nil
+}
+
+// oauthTokenRequest returns a POST request for a token using an identity
+// token as an OAuth2 refresh token.
+// https://docs.docker.com/registry/spec/auth/oauth/#refresh-token-format
+func oauthTokenRequest(req *http.Request, realm *url.URL, c challenge, identityToken string) (*http.Request, error) {
+	form := url.Values{}
+	form.Set("grant_type", "refresh_token")
+	form.Set("refresh_token", identityToken)
+	form.Set("client_id", oauthClientID)
+	if service, ok := c.params["service"]; ok {
+		form.Set("service", service)
+	}
+	form.Set("scope", c.params["scope"])
+	body := strings.NewReader(form.Encode())
+	tr, err := http.NewRequestWithContext(req.Context(), http.MethodPost, realm.String(), body)
+	if err != nil {
+		return nil, err
+	}
+	tr.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	return tr, nil
+}
+
+func decodeToken(resp *http.Response) (token, error) {
+	var tr tokenResponse
+	if err := json.NewDecoder(resp.Body).Decode(&tr); err != nil {
+		return token{}, fmt.Errorf("cannot decode token response: %w", err)
+	}
+	tok := token{value: tr.Token}
+	if tok.value == "" {
+		tok.value = tr.AccessToken
+	}
+	if tok.value == "" {
+		return token{}, fmt.Errorf("token response contains no token")
+	}
+	issued := tr.IssuedAt
+	if issued.IsZero() {
+		issued = time.Now()
+	}
+	expiry := time.Duration(tr.ExpiresIn) * time.Second
+	if expiry < defaultTokenExpiry {
+		expiry = defaultTokenExpiry
+	}
+	tok.expires = issued.Add(expiry)
+	return tok, nil
+}
+
+// resourceKey identifies the registry resource a request operates on and
+// whether it reads or writes it. Requests with the same key require the
+// same token scope.
+func resourceKey(req *http.Request) string {
+	access := "pull"
+	switch req.Method {
+	case http.MethodGet, http.MethodHead:
+	case http.MethodDelete:
+		access = "delete"
+	default:
+		access = "push"
+	}
+	path := strings.TrimPrefix(req.URL.Path, "/v2/")
+	for _, sep := range []string{"/manifests/", "/blobs/", "/tags/"} {
+		if i := strings.LastIndex(path, sep); i >= 0 {
+			return req.URL.Host + " " + path[:i] + " " + access
+		}
+	}
+	return req.URL.Host + " " + path + " " + access
+}
+
+// parseChallenge parses a WWW-Authenticate header value of the form
+//
+//	Bearer realm="https://auth.example.com/token",service="example.com",scope="repository:foo:pull"
+//
+// It only parses the first challenge if there are several. The returned
+// scheme is lower cased.
+func parseChallenge(header string) (challenge, bool) {
+	scheme, rest := cut(strings.TrimSpace(header), " ")
+	if scheme == "" {
+		return challenge{}, false
+	}
+	c := challenge{scheme: strings.ToLower(scheme), params: map[string]string{}}
+	for {
+		rest = strings.TrimLeft(rest, " ,")
+		if rest == "" {
+			return c, true
+		}
+		var key string
+		key, rest = cut(rest, "=")
+		key = strings.ToLower(strings.TrimSpace(key))
+		var val string
+		if strings.HasPrefix(rest, `"`) {
+			val, rest = unquote(rest[1:])
+		} else {
+			val, rest = cut(rest, ",")
+		}
+		c.params[key] = val
+	}
+}
+
+// cut slices s around the first instance of sep, returning the text before
+// and after sep. If sep is not found, it returns s and "".
+func cut(s, sep string) (string, string) {
+	if i := strings.Index(s, sep); i >= 0 {
+		return s[:i], s[i+len(sep):]
+	}
+	return s, ""
+}
+
+// unquote returns the contents of a quoted string up to the closing quote,
+// handling backslash escapes, and the remainder after the closing quote.
+func unquote(s string) (string, string) {
+	var sb strings.Builder
+	for i := 0; i < len(s); i++ {
+		switch s[i] {
+		case '"':
+			return sb.String(), s[i+1:]
+		case '\\':
+			if i+1 < len(s) {
+				i++
+			}
+		}
+		sb.WriteByte(s[i])
+	}
+	return sb.String(), ""
+}
+
+// isSecure returns true if it is safe to send credentials to u: either
+// it uses https or it refers to a loopback address.
+func isSecure(u *url.URL) bool {
+	if u.Scheme != "http" {
+		return true
+	}
+	ips, err := net.LookupIP(u.Hostname())
+	if err != nil {
+		return false
+	}
+	for _, ip := range ips {
+		if !ip.IsLoopback() {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,296 @@
+package registry
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"sync"
+	"testing"
+)
+
+func TestParseChallenge(t *testing.T) {
+	tests := []struct {
+		header string
+		want   challenge
+		ok     bool
+	}{
+		{
+			header: `Bearer realm="https://auth.example.com/token",service="registry.example.com",scope="repository:a/b:pull"`,
+			want: challenge{scheme: "bearer", params: map[string]string{
+				"realm":   "https://auth.example.com/token",
+				"service": "registry.example.com",
+				"scope":   "repository:a/b:pull",
+			}},
+			ok: true,
+		},
+		{
+			header: `bearer Realm="https://auth.example.com/token", scope="repository:a:pull,push repository:b:pull"`,
+			want: challenge{scheme: "bearer", params: map[string]string{
+				"realm": "https://auth.example.com/token",
+				"scope": "repository:a:pull,push repository:b:pull",
+			}},
+			ok: true,
+		},
+		{
+			header: `Bearer realm=https://auth.example.com/token,error=invalid_token`,
+			want: challenge{scheme: "bearer", params: map[string]string{
+				"realm": "https://auth.example.com/token",
+				"error": "invalid_token",
+			}},
+			ok: true,
+		},
+		{
+			header: `Bearer realm="quoted \"realm\""`,
+			want:   challenge{scheme: "bearer", params: map[string]string{"realm": `quoted "realm"`}},
+			ok:     true,
+		},
+		{
+			header: `Basic realm="registry"`,
+			want:   challenge{scheme: "basic", params: map[string]string{"realm": "registry"}},
+			ok:     true,
+		},
+		{header: "", ok: false},
+	}
+	for _, tt := range tests {
+		got, ok := parseChallenge(tt.header)
+		if ok != tt.ok || ok && !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("parseChallenge(%q) = %v, %t, want %v, %t", tt.header, got, ok, tt.want, tt.ok)
+		}
+	}
+}
+
+// tokenTestServer is a fake registry that requires bearer tokens issued by
+// its fake token server.
+type tokenTestServer struct {
+	t        *testing.T
+	registry *httptest.Server
+	auth     *httptest.Server
+	// service is the service of the challenge, if not empty.
+	service string
+
+	mu sync.Mutex
+	// valid are the tokens the registry accepts, by token.
+	valid map[string]bool
+	// tokenRequests are the token requests received.
+	tokenRequests []*http.Request
+	// challenges is the number of 401 responses sent by the registry.
+	challenges int
+}
+
+func newTokenTestServer(t *testing.T) *tokenTestServer {
+	ts := &tokenTestServer{t: t, service: "test-registry", valid: map[string]bool{}}
+	ts.auth = httptest.NewServer(http.HandlerFunc(ts.serveToken))
+	t.Cleanup(ts.auth.Close)
+	ts.registry = httptest.NewServer(http.HandlerFunc(ts.serveRegistry))
+	t.Cleanup(ts.registry.Close)
+	return ts
+}
+
+func (ts *tokenTestServer) serveRegistry(w http.ResponseWriter, r *http.Request) {
+	tok := strings.TrimPrefix(r.Header.Get("Authorization"), "Bearer ")
+	ts.mu.Lock()
+	ok := ts.valid[tok]
+	if !ok {
+		ts.challenges++
+	}
+	ts.mu.Unlock()
+	if ok {
+		fmt.Fprintln(w, "{}")
+		return
+	}
+	challenge := fmt.Sprintf(`Bearer realm="%s/token",scope="%s"`, ts.auth.URL, scopeOf(r))
+	if ts.service != "" {
+		challenge += fmt.Sprintf(`,service="%s"`, ts.service)
+	}
+	w.Header().Set("WWW-Authenticate", challenge)
+	w.WriteHeader(http.StatusUnauthorized)
+}
+
+// scopeOf returns the token scope a request to the registry needs.
+func scopeOf(r *http.Request) string {
+	path := strings.TrimPrefix(r.URL.Path, "/v2/")
+	for _, sep := range []string{"/manifests/", "/blobs/", "/tags/"} {
+		if i := strings.LastIndex(path, sep); i >= 0 {
+			path = path[:i]
+		}
+	}
+	access := "pull"
+	if r.Method != http.MethodGet && r.Method != http.MethodHead {
+		access = "pull,push"
+	}
+	return "repository:" + path + ":" + access
+}
+
+func (ts *tokenTestServer) serveToken(w http.ResponseWriter, r *http.Request) {
+	if err := r.ParseForm(); err != nil {
+		ts.t.Error(err)
+	}
+	ts.mu.Lock()
+	ts.tokenRequests = append(ts.tokenRequests, r)
+	tok := fmt.Sprintf("token-%d", len(ts.tokenRequests))
+	ts.valid[tok] = true
+	ts.mu.Unlock()
+	if user, pass, ok := r.BasicAuth(); ok && (user != "user" || pass != "secret") {
+		w.WriteHeader(http.StatusUnauthorized)
+		return
+	}
+	_ = json.NewEncoder(w).Encode(tokenResponse{Token: tok, ExpiresIn: 300})
+}
+
+// revoke makes the registry reject the tokens issued so far.
+func (ts *tokenTestServer) revoke() {
+	ts.mu.Lock()
+	defer ts.mu.Unlock()
+	ts.valid = map[string]bool{}
+}
+
+func (ts *tokenTestServer) counts() (int, int) {
+	ts.mu.Lock()
+	defer ts.mu.Unlock()
+	return len(ts.tokenRequests), ts.challenges
+}
+
+func (ts *tokenTestServer) lastTokenRequest() *http.Request {
+	ts.t.Helper()
+	ts.mu.Lock()
+	defer ts.mu.Unlock()
+	if len(ts.tokenRequests) == 0 {
+		ts.t.Fatal("no token requests")
+	}
+	return ts.tokenRequests[len(ts.tokenRequests)-1]
+}
+
+func (ts *tokenTestServer) client(creds credentials) *http.Client {
+	host := strings.TrimPrefix(ts.registry.URL, "http://")
+	return &http.Client{Transport: newAuthTransport(host, func() (credentials, error) { return creds, nil })}
+}
+
+// get makes a GET request to the registry and returns the response status.
+func (ts *tokenTestServer) get(client *http.Client, path string) int {
+	ts.t.Helper()
+	resp, err := client.Get(ts.registry.URL + path)
+	if err != nil {
+		ts.t.Fatal(err)
+	}
+	resp.Body.Close()
+	return resp.StatusCode
+}
+
+func TestAuthTransportScopeAndService(t *testing.T) {
+	ts := newTokenTestServer(t)
+	client := ts.client(credentials{username: "user", password: "secret"})
+	if got := ts.get(client, "/v2/a/b/tags/list"); got != http.StatusOK {
+		t.Fatalf("got status %d, want %d", got, http.StatusOK)
+	}
+	r := ts.lastTokenRequest()
+	if r.Method != http.MethodGet {
+		t.Errorf("got token request method %s, want GET", r.Method)
+	}
+	if got := r.Form.Get("service"); got != "test-registry" {
+		t.Errorf("got service %q, want %q", got, "test-registry")
+	}
+	if got := r.Form["scope"]; !reflect.DeepEqual(got, []string{"repository:a/b:pull"}) {
+		t.Errorf("got scope %q, want %q", got, "repository:a/b:pull")
+	}
+	if user, pass, ok := r.BasicAuth(); !ok || user != "user" || pass != "secret" {
+		t.Errorf("got basic auth %q, %q, %t, want credentials", user, pass, ok)
+	}
+
+	// The service parameter is only sent if it is in the challenge.
+	ts.service = ""
+	ts.get(client, "/v2/c/tags/list")
+	if _, ok := ts.lastTokenRequest().Form["service"]; ok {
+		t.Error("sent service parameter not in challenge")
+	}
+}
+
+func TestAuthTransportOAuth(t *testing.T) {
+	ts := newTokenTestServer(t)
+	client := ts.client(credentials{identityToken: "refresh"})
+	ts.get(client, "/v2/a/tags/list")
+	r := ts.lastTokenRequest()
+	if r.Method != http.MethodPost {
+		t.Fatalf("got token request method %s, want POST", r.Method)
+	}
+	want := map[string]string{
+		"grant_type":    "refresh_token",
+		"refresh_token": "refresh",
+		"client_id":     oauthClientID,
+		"service":       "test-registry",
+		"scope":         "repository:a:pull",
+	}
+	for key, val := range want {
+		if got := r.PostForm.Get(key); got != val {
+			t.Errorf("got %s %q, want %q", key, got, val)
+		}
+	}
+}
+
+func TestAuthTransportTokenCache(t *testing.T) {
+	ts := newTokenTestServer(t)
+	client := ts.client(credentials{})
+	for i := 0; i < 3; i++ {
+		ts.get(client, "/v2/a/tags/list")
+		ts.get(client, "/v2/a/manifests/latest")
+	}
+	// The token for the scope is fetched once and then sent up front.
+	if tokens, challenges := ts.counts(); tokens != 1 || challenges != 1 {
+		t.Errorf("got %d token requests and %d challenges, want 1 and 1", tokens, challenges)
+	}
+	// Another scope needs another token.
+	ts.get(client, "/v2/b/tags/list")
+	ts.get(client, "/v2/b/tags/list")
+	if tokens, challenges := ts.counts(); tokens != 2 || challenges != 2 {
+		t.Errorf("got %d token requests and %d challenges, want 2 and 2", tokens, challenges)
+	}
+}
+
+func TestAuthTransportRechallenge(t *testing.T) {
+	ts := newTokenTestServer(t)
+	client := ts.client(credentials{})
+	ts.get(client, "/v2/a/tags/list")
+	ts.revoke()
+	// A cached token that is rejected is replaced with a new one.
+	if got := ts.get(client, "/v2/a/tags/list"); got != http.StatusOK {
+		t.Fatalf("got status %d for request with revoked token, want %d", got, http.StatusOK)
+	}
+	if tokens, challenges := ts.counts(); tokens != 2 || challenges != 2 {
+		t.Errorf("got %d token requests and %d challenges, want 2 and 2", tokens, challenges)
+	}
+	ts.get(client, "/v2/a/tags/list")
+	if tokens, _ := ts.counts(); tokens != 2 {
+		t.Errorf("got %d token requests, want new token cached", tokens)
+	}
+}
+
+func TestAuthTransportTokenDenied(t *testing.T) {
+	ts := newTokenTestServer(t)
+	client := ts.client(credentials{username: "user", password: "wrong"})
+	// A denied token request returns the registry's 401.
+	if got := ts.get(client, "/v2/a/tags/list"); got != http.StatusUnauthorized {
+		t.Errorf("got status %d, want %d", got, http.StatusUnauthorized)
+	}
+}
+
+func TestAuthTransportOtherHost(t *testing.T) {
+	ts := newTokenTestServer(t)
+	var got string
+	blobs := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		got = r.Header.Get("Authorization")
+	}))
+	defer blobs.Close()
+	client := ts.client(credentials{username: "user", password: "secret"})
+	// Requests to hosts other than the registry, such as blob storage
+	// redirects, are sent without credentials.
+	resp, err := client.Get(blobs.URL + "/v2/a/blobs/sha256:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	if got != "" {
+		t.Errorf("got Authorization %q sent to other host, want none", got)
+	}
+}
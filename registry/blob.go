@@ -0,0 +1,112 @@
+package registry
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"hash"
+	"io"
+	"strings"
+
+	"foxygo.at/dreg/pb"
+)
+
+// ReadBlob fetches the blob with the given digest from the named repository
+// and verifies that its contents match the digest.
+func (c *Client) ReadBlob(ctx context.Context, name, digest string) ([]byte, error) {
+	r, err := c.OpenBlob(ctx, name, digest, 0)
+	if err != nil {
+		return nil, err
+	}
+	b, err := io.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+	if err := VerifyDigest(digest, b); err != nil {
+		return nil, fmt.Errorf("%s@%s: %w", name, digest, err)
+	}
+	return b, nil
+}
+
+// OpenBlob returns a reader for the contents of the blob with the given
+// digest in the named repository, starting at offset. The contents are not
+// verified against the digest.
+func (c *Client) OpenBlob(ctx context.Context, name, digest string, offset uint64) (io.Reader, error) {
+	req := &pb.GetBlobRequest{Name: name, Digest: digest, Offset: offset}
+	stream, err := c.GetBlob(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+	return &blobReader{stream: stream}, nil
+}
+
+// blobReader is an io.Reader over the chunks of a GetBlob stream.
+type blobReader struct {
+	stream pb.Registry_GetBlobClient
+	buf    []byte
+}
+
+func (r *blobReader) Read(p []byte) (int, error) {
+	for len(r.buf) == 0 {
+		resp, err := r.stream.Recv()
+		if err != nil {
+			return 0, err
+		}
+		r.buf = resp.Data
+	}
+	n := copy(p, r.buf)
+	r.buf = r.buf[n:]
+	return n, nil
+}
+
+// VerifyDigest returns an error if digest is not the sha256 digest of b.
+// Digests using other algorithms are not verified.
+func VerifyDigest(digest string, b []byte) error {
+	if !strings.HasPrefix(digest, "sha256:") {
+		return nil
+	}
+	if got := SHA256Digest(b); got != digest {
+		return fmt.Errorf("digest mismatch: got %s", got)
+	}
+	return nil
+}
+
+// VerifyingReader returns a reader of r that returns an error instead of
+// io.EOF if what was read does not match digest. As with VerifyDigest,
+// digests using other algorithms are not verified.
+func VerifyingReader(r io.Reader, digest string) io.Reader {
+	if !strings.HasPrefix(digest, "sha256:") {
+		return r
+	}
+	return &verifyingReader{r: r, h: sha256.New(), digest: digest}
+}
+
+type verifyingReader struct {
+	r      io.Reader
+	h      hash.Hash
+	digest string
+}
+
+func (v *verifyingReader) Read(p []byte) (int, error) {
+	n, err := v.r.Read(p)
+	v.h.Write(p[:n])
+	if errors.Is(err, io.EOF) {
+		if got := "sha256:" + hex.EncodeToString(v.h.Sum(nil)); got != v.digest {
+			return n, fmt.Errorf("%s: digest mismatch: got %s", v.digest, got)
+		}
+	}
+	return n, err
+}
+
+// SplitDigest splits digest into its algorithm and hex encoded hash. If
+// digest has no algorithm, it returns digest and "".
+func SplitDigest(digest string) (string, string) {
+	return cut(digest, ":")
+}
+
+func SHA256Digest(b []byte) string {
+	sum := sha256.Sum256(b)
+	return "sha256:" + hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,574 @@
+package registry
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"google.golang.org/genproto/googleapis/api/annotations"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/reflect/protoregistry"
+	"google.golang.org/protobuf/types/known/durationpb"
+)
+
+// rawConn is a grpc.ClientConnInterface that makes HTTP requests for
+// methods according to their google.api.http annotation. Bodies mapped to
+// a bytes field, such as manifests and blobs, are sent and received as is
+// and other bodies are JSON encoded. Error responses are converted to gRPC
+// status errors by httpError for all methods.
+//
+// In addition to the standard HttpRule, rawConn supports additional
+// bindings with a custom pattern of kind "header" to set a request header
+// and "response_header" to set a response field from a header. Both take
+// a path of the form "Header-Name: value" where value may contain field
+// references such as "{digest}". A request header is not sent if it
+// references an empty field. A custom pattern of kind "url" with a path
+// of a single field reference, such as "{location}", sends the request to
+// the URL in that field instead of the rule's path if the field is set.
+// The URL may be relative to the registry URL.
+type rawConn struct {
+	baseURL string
+	client  *http.Client
+}
+
+// httpRule is the HTTP binding of a method, as parsed from its
+// google.api.http annotation.
+type httpRule struct {
+	method          string
+	path            string
+	body            string
+	responseBody    string
+	headers         []string
+	responseHeaders []string
+	urlField        string
+}
+
+var fieldRefRE = regexp.MustCompile(`\{([a-z_]+)(=[^}]*)?\}`)
+
+func newRawConn(baseURL string, client *http.Client) *rawConn {
+	return &rawConn{baseURL: strings.TrimSuffix(baseURL, "/"), client: client}
+}
+
+func (c *rawConn) Invoke(ctx context.Context, method string, args, reply interface{}, opts ...grpc.CallOption) error {
+	rule, err := lookupRule(method)
+	if err != nil {
+		return err
+	}
+	req, resp := args.(proto.Message).ProtoReflect(), reply.(proto.Message).ProtoReflect()
+	httpResp, err := c.do(ctx, rule, req)
+	if err != nil {
+		return err
+	}
+	defer httpResp.Body.Close()
+	return readResponse(httpResp, rule, resp)
+}
+
+// NewStream returns a stream for server-streaming methods with a bytes
+// response body. The response body is received in chunks of up to
+// streamChunkSize bytes.
+func (c *rawConn) NewStream(ctx context.Context, desc *grpc.StreamDesc, method string, opts ...grpc.CallOption) (grpc.ClientStream, error) {
+	rule, err := lookupRule(method)
+	if err != nil {
+		return nil, err
+	}
+	if desc.ClientStreams || rule.responseBody == "" {
+		return nil, status.Errorf(codes.Unimplemented, "streaming method %s is not supported", method)
+	}
+	return &rawStream{ctx: ctx, conn: c, rule: rule}, nil
+}
+
+// Maximum size of each message received on a rawStream.
+const streamChunkSize = 64 * 1024
+
+// rawStream is a grpc.ClientStream for a server-streaming method with a
+// bytes response body. The HTTP request is made when the client closes the
+// send direction of the stream after sending the request message.
+type rawStream struct {
+	ctx  context.Context
+	conn *rawConn
+	rule *httpRule
+	req  protoreflect.Message
+	resp *http.Response
+	// headerRead is set once the response headers have been read into
+	// the first message received.
+	headerRead bool
+}
+
+func (s *rawStream) Header() (metadata.MD, error) { return nil, nil }
+func (s *rawStream) Trailer() metadata.MD         { return nil }
+func (s *rawStream) Context() context.Context     { return s.ctx }
+
+func (s *rawStream) SendMsg(m interface{}) error {
+	s.req = m.(proto.Message).ProtoReflect()
+	return nil
+}
+
+func (s *rawStream) CloseSend() error {
+	resp, err := s.conn.do(s.ctx, s.rule, s.req)
+	if err != nil {
+		return err
+	}
+	s.resp = resp
+	return nil
+}
+
+func (s *rawStream) RecvMsg(m interface{}) error {
+	if s.resp == nil {
+		return io.EOF
+	}
+	msg := m.(proto.Message).ProtoReflect()
+	if !s.headerRead {
+		s.headerRead = true
+		if err := readResponseHeaders(s.resp.Header, s.rule, msg); err != nil {
+			return s.close(err)
+		}
+	}
+	buf := make([]byte, streamChunkSize)
+	n, err := io.ReadFull(s.resp.Body, buf)
+	if n == 0 {
+		if err == io.ErrUnexpectedEOF {
+			err = io.EOF
+		}
+		return s.close(err)
+	}
+	msg.Set(fieldByName(msg, s.rule.responseBody), protoreflect.ValueOfBytes(buf[:n]))
+	return nil
+}
+
+// close closes the response body and returns err, or io.EOF if err is nil.
+func (s *rawStream) close(err error) error {
+	s.resp.Body.Close()
+	s.resp = nil
+	if err == nil {
+		err = io.EOF
+	}
+	return err
+}
+
+// do makes the HTTP request for rule with the fields of req and returns
+// the response if it has a 2xx status. Otherwise it returns a gRPC status
+// error for the response status.
+func (c *rawConn) do(ctx context.Context, rule *httpRule, req protoreflect.Message) (*http.Response, error) {
+	used := map[string]bool{}
+	path := expandFields(rule.path, req, used, true)
+	header := http.Header{}
+	for _, h := range rule.headers {
+		key, val := cut(h, ":")
+		refs := map[string]bool{}
+		val = strings.TrimSpace(expandFields(val, req, refs, false))
+		for ref := range refs {
+			used[ref] = true
+		}
+		if val == "" || hasEmptyField(req, refs) {
+			continue
+		}
+		header.Set(strings.TrimSpace(key), val)
+	}
+	var body io.Reader
+	if rule.body != "" {
+		b, err := requestBody(rule, req, used)
+		if err != nil {
+			return nil, err
+		}
+		body = bytes.NewReader(b)
+		if !isBytesField(req, rule.body) {
+			header.Set("Content-Type", "application/json")
+		}
+	}
+
+	u, err := url.Parse(c.baseURL + path)
+	if err != nil {
+		return nil, err
+	}
+	if rule.urlField != "" {
+		used[rule.urlField] = true
+		if loc := req.Get(fieldByName(req, rule.urlField)).String(); loc != "" {
+			if u, err = u.Parse(loc); err != nil {
+				return nil, status.Errorf(codes.InvalidArgument, "invalid %s %q: %v", rule.urlField, loc, err)
+			}
+		}
+	}
+	q := u.Query()
+	for k, v := range queryParams(req, used) {
+		q[k] = v
+	}
+	u.RawQuery = q.Encode()
+	httpReq, err := http.NewRequestWithContext(ctx, rule.method, u.String(), body)
+	if err != nil {
+		return nil, err
+	}
+	for k, v := range header {
+		httpReq.Header[k] = v
+	}
+	resp, err := c.client.Do(httpReq)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		defer resp.Body.Close()
+		return nil, httpError(resp)
+	}
+	return resp, nil
+}
+
+// requestBody returns the request body for rule: the bytes of a bytes body
+// field as is, or the JSON encoding of a message body field or, for "*",
+// of the fields not used in the path or headers. The body fields are
+// added to used.
+func requestBody(rule *httpRule, req protoreflect.Message, used map[string]bool) ([]byte, error) {
+	if rule.body != "*" {
+		used[rule.body] = true
+		v := req.Get(fieldByName(req, rule.body))
+		if isBytesField(req, rule.body) {
+			return v.Bytes(), nil
+		}
+		return protojson.Marshal(v.Message().Interface())
+	}
+	body := proto.Clone(req.Interface()).ProtoReflect()
+	fields := body.Descriptor().Fields()
+	for i := 0; i < fields.Len(); i++ {
+		name := string(fields.Get(i).Name())
+		if used[name] {
+			body.Clear(fields.Get(i))
+		}
+		used[name] = true
+	}
+	return protojson.Marshal(body.Interface())
+}
+
+// readResponse populates msg from the response headers and body according
+// to rule.
+func readResponse(resp *http.Response, rule *httpRule, msg protoreflect.Message) error {
+	if rule.responseBody != "" || hasJSONResponse(rule, msg) {
+		b, err := io.ReadAll(resp.Body)
+		if err != nil {
+			return err
+		}
+		if err := readResponseBody(b, rule, msg); err != nil {
+			return err
+		}
+	}
+	return readResponseHeaders(resp.Header, rule, msg)
+}
+
+// readResponseBody sets msg, or its response body field, from the body b.
+// Empty JSON bodies are ignored.
+func readResponseBody(b []byte, rule *httpRule, msg protoreflect.Message) error {
+	if rule.responseBody != "" && isBytesField(msg, rule.responseBody) {
+		msg.Set(fieldByName(msg, rule.responseBody), protoreflect.ValueOfBytes(b))
+		return nil
+	}
+	if len(bytes.TrimSpace(b)) == 0 {
+		return nil
+	}
+	target := msg
+	if rule.responseBody != "" {
+		target = msg.Mutable(fieldByName(msg, rule.responseBody)).Message()
+	}
+	if err := (protojson.UnmarshalOptions{DiscardUnknown: true}).Unmarshal(b, target.Interface()); err != nil {
+		return status.Errorf(codes.Internal, "invalid response body: %v", err)
+	}
+	return nil
+}
+
+func readResponseHeaders(header http.Header, rule *httpRule, msg protoreflect.Message) error {
+	for _, h := range rule.responseHeaders {
+		key, ref := cut(h, ":")
+		m := fieldRefRE.FindStringSubmatch(strings.TrimSpace(ref))
+		val := header.Get(strings.TrimSpace(key))
+		if m == nil || val == "" {
+			continue
+		}
+		fd := fieldByName(msg, m[1])
+		v, err := parseValue(fd, val)
+		if err != nil {
+			return status.Errorf(codes.Internal, "invalid %s header %q: %v", key, val, err)
+		}
+		msg.Set(fd, v)
+	}
+	return nil
+}
+
+// lookupRule returns the HTTP binding of a gRPC method given as
+// "/package.Service/Method".
+func lookupRule(method string) (*httpRule, error) {
+	name := strings.ReplaceAll(strings.TrimPrefix(method, "/"), "/", ".")
+	d, err := protoregistry.GlobalFiles.FindDescriptorByName(protoreflect.FullName(name))
+	if err != nil {
+		return nil, status.Errorf(codes.Unimplemented, "unknown method %s", method)
+	}
+	md, ok := d.(protoreflect.MethodDescriptor)
+	if !ok {
+		return nil, status.Errorf(codes.Unimplemented, "unknown method %s", method)
+	}
+	r, ok := proto.GetExtension(md.Options(), annotations.E_Http).(*annotations.HttpRule)
+	if !ok || r == nil {
+		return nil, status.Errorf(codes.Unimplemented, "no http rule for method %s", method)
+	}
+	rule := &httpRule{body: r.Body, responseBody: r.ResponseBody}
+	rule.method, rule.path = rulePattern(r)
+	for _, ab := range r.AdditionalBindings {
+		switch strings.ToLower(ab.GetCustom().GetKind()) {
+		case "header":
+			rule.headers = append(rule.headers, ab.GetCustom().GetPath())
+		case "response_header":
+			rule.responseHeaders = append(rule.responseHeaders, ab.GetCustom().GetPath())
+		case "url":
+			rule.urlField = strings.Trim(ab.GetCustom().GetPath(), "{}")
+		}
+	}
+	return rule, nil
+}
+
+func rulePattern(r *annotations.HttpRule) (string, string) {
+	switch p := r.Pattern.(type) {
+	case *annotations.HttpRule_Get:
+		return http.MethodGet, p.Get
+	case *annotations.HttpRule_Put:
+		return http.MethodPut, p.Put
+	case *annotations.HttpRule_Post:
+		return http.MethodPost, p.Post
+	case *annotations.HttpRule_Delete:
+		return http.MethodDelete, p.Delete
+	case *annotations.HttpRule_Patch:
+		return http.MethodPatch, p.Patch
+	case *annotations.HttpRule_Custom:
+		return strings.ToUpper(p.Custom.Kind), p.Custom.Path
+	}
+	return "", ""
+}
+
+// expandFields replaces field references such as "{name}" or "{name=**}"
+// in tmpl with the values of those fields in msg, recording the field
+// names in used. Values are path escaped if escape is true, except for
+// multi-segment ("**") references whose slashes are preserved.
+func expandFields(tmpl string, msg protoreflect.Message, used map[string]bool, escape bool) string {
+	return fieldRefRE.ReplaceAllStringFunc(tmpl, func(ref string) string {
+		m := fieldRefRE.FindStringSubmatch(ref)
+		used[m[1]] = true
+		val := formatValue(msg.Get(fieldByName(msg, m[1])))
+		if !escape {
+			return val
+		}
+		if m[2] == "=**" {
+			segs := strings.Split(val, "/")
+			for i, seg := range segs {
+				segs[i] = url.PathEscape(seg)
+			}
+			return strings.Join(segs, "/")
+		}
+		return url.PathEscape(val)
+	})
+}
+
+// queryParams returns the populated fields of msg not in used as URL
+// query parameters.
+func queryParams(msg protoreflect.Message, used map[string]bool) url.Values {
+	q := url.Values{}
+	msg.Range(func(fd protoreflect.FieldDescriptor, v protoreflect.Value) bool {
+		name := string(fd.Name())
+		if used[name] || fd.Kind() == protoreflect.MessageKind || fd.Kind() == protoreflect.BytesKind {
+			return true
+		}
+		if fd.IsList() {
+			for i := 0; i < v.List().Len(); i++ {
+				q.Add(name, formatValue(v.List().Get(i)))
+			}
+			return true
+		}
+		q.Set(name, formatValue(v))
+		return true
+	})
+	return q
+}
+
+func hasEmptyField(msg protoreflect.Message, names map[string]bool) bool {
+	for name := range names {
+		if !msg.Has(fieldByName(msg, name)) {
+			return true
+		}
+	}
+	return false
+}
+
+// hasJSONBody returns true if the request for rule has a JSON body or its
+// response has a JSON body, which is the case unless the body is mapped to
+// a bytes field, or for responses without a body, all response fields are
+// read from headers.
+func hasJSONBody(rule *httpRule, req, resp protoreflect.Message) bool {
+	if rule.body != "" && !isBytesField(req, rule.body) {
+		return true
+	}
+	return hasJSONResponse(rule, resp)
+}
+
+// hasJSONResponse returns true if the response for rule has a JSON body.
+func hasJSONResponse(rule *httpRule, resp protoreflect.Message) bool {
+	if rule.responseBody != "" {
+		return !isBytesField(resp, rule.responseBody)
+	}
+	fromHeaders := map[string]bool{}
+	for _, h := range rule.responseHeaders {
+		_, ref := cut(h, ":")
+		if m := fieldRefRE.FindStringSubmatch(ref); m != nil {
+			fromHeaders[m[1]] = true
+		}
+	}
+	fields := resp.Descriptor().Fields()
+	for i := 0; i < fields.Len(); i++ {
+		if !fromHeaders[string(fields.Get(i).Name())] {
+			return true
+		}
+	}
+	return false
+}
+
+func isBytesField(msg protoreflect.Message, name string) bool {
+	fd := msg.Descriptor().Fields().ByName(protoreflect.Name(name))
+	return fd != nil && fd.Kind() == protoreflect.BytesKind && !fd.IsList()
+}
+
+// fieldByName returns the field descriptor of the named field of msg. It
+// panics if there is no such field as that is an error in the proto
+// annotations.
+func fieldByName(msg protoreflect.Message, name string) protoreflect.FieldDescriptor {
+	fd := msg.Descriptor().Fields().ByName(protoreflect.Name(name))
+	if fd == nil {
+		panic(fmt.Sprintf("no field %q in %s", name, msg.Descriptor().FullName()))
+	}
+	return fd
+}
+
+func formatValue(v protoreflect.Value) string {
+	if b, ok := v.Interface().([]byte); ok {
+		return string(b)
+	}
+	return v.String()
+}
+
+func parseValue(fd protoreflect.FieldDescriptor, s string) (protoreflect.Value, error) {
+	switch fd.Kind() {
+	case protoreflect.StringKind:
+		return protoreflect.ValueOfString(s), nil
+	case protoreflect.BytesKind:
+		return protoreflect.ValueOfBytes([]byte(s)), nil
+	case protoreflect.BoolKind:
+		b, err := strconv.ParseBool(s)
+		return protoreflect.ValueOfBool(b), err
+	case protoreflect.Int32Kind, protoreflect.Sint32Kind, protoreflect.Sfixed32Kind:
+		i, err := strconv.ParseInt(s, 10, 32)
+		return protoreflect.ValueOfInt32(int32(i)), err
+	case protoreflect.Int64Kind, protoreflect.Sint64Kind, protoreflect.Sfixed64Kind:
+		i, err := strconv.ParseInt(s, 10, 64)
+		return protoreflect.ValueOfInt64(i), err
+	case protoreflect.Uint32Kind, protoreflect.Fixed32Kind:
+		i, err := strconv.ParseUint(s, 10, 32)
+		return protoreflect.ValueOfUint32(uint32(i)), err
+	case protoreflect.Uint64Kind, protoreflect.Fixed64Kind:
+		i, err := strconv.ParseUint(s, 10, 64)
+		return protoreflect.ValueOfUint64(i), err
+	}
+	return protoreflect.Value{}, fmt.Errorf("unsupported field type %s", fd.Kind())
+}
+
+// registryErrors is the error body returned by a registry.
+// https://docs.docker.com/registry/spec/api/#errors
+type registryErrors struct {
+	Errors []registryError `json:"errors"`
+}
+
+type registryError struct {
+	Code    string `json:"code"`
+	Message string `json:"message"`
+}
+
+// httpError returns a gRPC status error for a non-2xx HTTP response,
+// using the registry error messages in the body if there are any.
+func httpError(resp *http.Response) error {
+	msg := resp.Status
+	b, _ := io.ReadAll(io.LimitReader(resp.Body, 64*1024))
+	var re registryErrors
+	if err := json.Unmarshal(b, &re); err == nil && len(re.Errors) > 0 {
+		msgs := make([]string, len(re.Errors))
+		for i, e := range re.Errors {
+			msgs[i] = e.Code + ": " + e.Message
+		}
+		msg = strings.Join(msgs, "; ")
+	}
+	st := status.New(httpStatusCode(resp.StatusCode), msg)
+	if resp.StatusCode == http.StatusTooManyRequests || resp.StatusCode >= 500 {
+		// Mark the error as transient for retryConn, with the delay
+		// to retry after if the registry gave one.
+		info := &errdetails.RetryInfo{}
+		if delay, ok := retryAfter(resp.Header.Get("Retry-After")); ok {
+			info.RetryDelay = durationpb.New(delay)
+		}
+		if withInfo, err := st.WithDetails(info); err == nil {
+			st = withInfo
+		}
+	}
+	return st.Err()
+}
+
+// retryAfter parses a Retry-After header value, which is either a number
+// of seconds or an HTTP date.
+func retryAfter(val string) (time.Duration, bool) {
+	if val == "" {
+		return 0, false
+	}
+	if secs, err := strconv.Atoi(val); err == nil && secs >= 0 {
+		return time.Duration(secs) * time.Second, true
+	}
+	t, err := http.ParseTime(val)
+	if err != nil {
+		return 0, false
+	}
+	if delay := time.Until(t); delay > 0 {
+		return delay, true
+	}
+	return 0, true
+}
+
+// httpStatusCode maps an HTTP status code to a gRPC status code.
+func httpStatusCode(code int) codes.Code {
+	switch code {
+	case http.StatusBadRequest:
+		return codes.InvalidArgument
+	case http.StatusUnauthorized:
+		return codes.Unauthenticated
+	case http.StatusForbidden:
+		return codes.PermissionDenied
+	case http.StatusNotFound:
+		return codes.NotFound
+	case http.StatusConflict:
+		return codes.Aborted
+	case http.StatusRequestedRangeNotSatisfiable:
+		return codes.OutOfRange
+	case http.StatusTooManyRequests:
+		return codes.ResourceExhausted
+	case http.StatusNotImplemented, http.StatusMethodNotAllowed:
+		return codes.Unimplemented
+	case http.StatusInternalServerError:
+		return codes.Internal
+	case http.StatusBadGateway, http.StatusServiceUnavailable:
+		return codes.Unavailable
+	case http.StatusGatewayTimeout:
+		return codes.DeadlineExceeded
+	}
+	return codes.Unknown
+}
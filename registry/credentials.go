@@ -0,0 +1,140 @@
+package registry
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"os/exec"
+	"strings"
+
+	"foxygo.at/dreg/reference"
+)
+
+// DockerConfig matches the structure of the docker config.json file, with just
+// the elements we are interested in.
+type DockerConfig struct {
+	Auths       map[string]DockerAuth
+	CredsStore  string
+	CredHelpers map[string]string
+}
+
+type DockerAuth struct {
+	Auth          string
+	IdentityToken string
+}
+
+// credentials for authenticating to a registry. If identityToken is set, it
+// is used as an OAuth2 refresh token to obtain bearer tokens, otherwise
+// username and password are used for basic auth.
+type credentials struct {
+	username      string
+	password      string
+	identityToken string
+}
+
+// credHelperResponse is the output of `docker-credential-<name> get`.
+// https://github.com/docker/docker-credential-helpers#development
+type credHelperResponse struct {
+	ServerURL string
+	Username  string
+	Secret    string
+}
+
+// Docker Hub credentials are stored under the URL of the legacy Docker Hub
+// index rather than the registry host.
+const (
+	dockerHubServerURL  = "https://index.docker.io/v1/"
+	dockerHubConfigHost = "index.docker.io"
+)
+
+// Credential helpers return this username when Secret is an identity token.
+const identityTokenUsername = "<token>"
+
+// credentials returns the credentials for the registry host, looking first
+// at a per-host credential helper, then the global credential store and
+// lastly the auths stored in the config file itself. The zero value is
+// returned if there are no credentials for host.
+func (d DockerConfig) credentials(host string) (credentials, error) {
+	serverURL := host
+	if host == reference.DockerHubRegistry {
+		// Docker stores Docker Hub credentials under its legacy index URL.
+		host, serverURL = dockerHubConfigHost, dockerHubServerURL
+	}
+	if helper := d.credHelper(host); helper != "" {
+		return credHelperGet(helper, serverURL)
+	}
+	for key, a := range d.Auths {
+		if configHostname(key) != host {
+			continue
+		}
+		c := credentials{identityToken: a.IdentityToken}
+		if a.Auth != "" {
+			b, err := base64.StdEncoding.DecodeString(a.Auth)
+			if err != nil {
+				return credentials{}, fmt.Errorf("invalid auth for %s in docker config: %w", key, err)
+			}
+			c.username, c.password = cut(string(b), ":")
+		}
+		return c, nil
+	}
+	return credentials{}, nil
+}
+
+func (d DockerConfig) credHelper(host string) string {
+	for key, helper := range d.CredHelpers {
+		if configHostname(key) == host {
+			return helper
+		}
+	}
+	return d.CredsStore
+}
+
+// credHelperGet runs the docker credential helper program for helper to get
+// the credentials for host.
+func credHelperGet(helper, host string) (credentials, error) {
+	prog := "docker-credential-" + helper
+	cmd := exec.Command(prog, "get")
+	cmd.Stdin = strings.NewReader(host)
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		msg := strings.TrimSpace(stdout.String() + stderr.String())
+		if strings.Contains(msg, "credentials not found") {
+			return credentials{}, nil
+		}
+		if msg != "" {
+			err = fmt.Errorf("%w: %s", err, msg)
+		}
+		return credentials{}, fmt.Errorf("%s: %w", prog, err)
+	}
+	var resp credHelperResponse
+	if err := json.Unmarshal(stdout.Bytes(), &resp); err != nil {
+		return credentials{}, fmt.Errorf("%s: cannot decode output: %w", prog, err)
+	}
+	if resp.Username == identityTokenUsername {
+		return credentials{identityToken: resp.Secret}, nil
+	}
+	return credentials{username: resp.Username, password: resp.Secret}, nil
+}
+
+// configHostname returns the hostname for a key in the docker config auths
+// or credHelpers, which may be a plain hostname or a URL.
+func configHostname(key string) string {
+	if !strings.Contains(key, "://") {
+		key = "//" + key
+	}
+	if u, err := url.Parse(key); err == nil && u.Host != "" {
+		return u.Host
+	}
+	return key
+}
+
+func (c credentials) basicAuth() string {
+	if c.username == "" && c.password == "" {
+		return ""
+	}
+	return base64.StdEncoding.EncodeToString([]byte(c.username + ":" + c.password))
+}
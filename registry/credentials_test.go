@@ -0,0 +1,118 @@
+package registry
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+)
+
+// credHelperScript is a docker credential helper that returns its name as
+// the username and the server URL as the secret. It returns an identity
+// token for servers starting with "token." and reports no credentials for
+// servers starting with "none.".
+const credHelperScript = `#!/bin/sh
+[ "$1" = get ] || exit 1
+read server
+case "$server" in
+  token.*) echo '{"ServerURL":"'$server'","Username":"<token>","Secret":"refresh"}';;
+  none.*) echo "credentials not found in native keychain"; exit 1;;
+  *) echo '{"ServerURL":"'$server'","Username":"%s","Secret":"'$server'"}';;
+esac
+`
+
+// installCredHelpers puts docker credential helpers with the given names
+// in a temporary directory at the front of PATH for the duration of the
+// test.
+func installCredHelpers(t *testing.T, names ...string) {
+	t.Helper()
+	if runtime.GOOS == "windows" {
+		t.Skip("credential helper scripts need a POSIX shell")
+	}
+	dir := t.TempDir()
+	for _, name := range names {
+		script := fmt.Sprintf(credHelperScript, name)
+		if err := os.WriteFile(filepath.Join(dir, "docker-credential-"+name), []byte(script), 0o755); err != nil {
+			t.Fatal(err)
+		}
+	}
+	path := os.Getenv("PATH")
+	t.Cleanup(func() { os.Setenv("PATH", path) })
+	os.Setenv("PATH", dir+string(os.PathListSeparator)+path)
+}
+
+func TestCredentialsHelpers(t *testing.T) {
+	installCredHelpers(t, "store", "helper")
+	d := DockerConfig{
+		Auths: map[string]DockerAuth{
+			"auths.example.com": {Auth: "dXNlcjpwYXNz"},
+		},
+		CredsStore: "store",
+		CredHelpers: map[string]string{
+			"helper.example.com":       "helper",
+			"https://url.example.com/": "helper",
+			"index.docker.io":          "helper",
+		},
+	}
+	tests := []struct {
+		host string
+		want credentials
+	}{
+		// The credsStore is used for hosts without a credHelper, even
+		// if they have auths.
+		{"other.example.com", credentials{username: "store", password: "other.example.com"}},
+		{"auths.example.com", credentials{username: "store", password: "auths.example.com"}},
+		// A credHelper takes precedence over the credsStore.
+		{"helper.example.com", credentials{username: "helper", password: "helper.example.com"}},
+		{"url.example.com", credentials{username: "helper", password: "url.example.com"}},
+		// Docker Hub credentials are stored under the legacy index URL.
+		{"registry-1.docker.io", credentials{username: "helper", password: dockerHubServerURL}},
+		{"token.example.com", credentials{identityToken: "refresh"}},
+		{"none.example.com", credentials{}},
+	}
+	for _, tt := range tests {
+		got, err := d.credentials(tt.host)
+		if err != nil {
+			t.Errorf("credentials(%q): %v", tt.host, err)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("credentials(%q) = %+v, want %+v", tt.host, got, tt.want)
+		}
+	}
+}
+
+func TestCredentialsAuths(t *testing.T) {
+	d := DockerConfig{Auths: map[string]DockerAuth{
+		"https://auths.example.com/v1/": {Auth: "dXNlcjpwYXNz"},
+		"token.example.com":             {IdentityToken: "refresh"},
+	}}
+	got, err := d.credentials("auths.example.com")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := (credentials{username: "user", password: "pass"}); got != want {
+		t.Errorf("got %+v, want %+v", got, want)
+	}
+	got, err = d.credentials("token.example.com")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := (credentials{identityToken: "refresh"}); got != want {
+		t.Errorf("got %+v, want %+v", got, want)
+	}
+}
+
+func TestCredentialsHelperNotFound(t *testing.T) {
+	installCredHelpers(t)
+	d := DockerConfig{CredsStore: "missing"}
+	_, err := d.credentials("example.com")
+	if err == nil {
+		t.Fatal("expected error for missing credential helper")
+	}
+	if !strings.Contains(err.Error(), "docker-credential-missing") {
+		t.Errorf("error %q does not name the helper", err)
+	}
+}
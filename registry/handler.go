@@ -0,0 +1,429 @@
+package registry
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"foxygo.at/dreg/pb"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
+)
+
+// handler is an http.Handler that serves the methods of a gRPC service
+// according to their google.api.http annotations. It is the server side
+// of rawConn: requests are decoded into request messages by the same
+// rules, including the custom "header" and "response_header" bindings,
+// and bytes bodies are sent as is. Other bodies are JSON. The custom
+// "url" binding is ignored as the rule's path is always served.
+type handler struct {
+	srv    interface{}
+	routes []route
+}
+
+// route is the HTTP binding of a method of the served service.
+type route struct {
+	// method is the full method name, such as
+	// "/foxygoat.dreg.Registry/GetManifest".
+	method string
+	rule   *httpRule
+	// pathRE matches the request path, capturing the values of the
+	// path's field references, which are named by fields.
+	pathRE *regexp.Regexp
+	fields []string
+	unary  *grpc.MethodDesc
+	stream *grpc.StreamDesc
+}
+
+// NewHandler returns an http.Handler serving the Docker Registry HTTP API
+// V2 with srv.
+func NewHandler(srv pb.RegistryServer) http.Handler {
+	return newHandler(&pb.Registry_ServiceDesc, srv)
+}
+
+func newHandler(desc *grpc.ServiceDesc, srv interface{}) *handler {
+	h := &handler{srv: srv}
+	for i := range desc.Methods {
+		md := &desc.Methods[i]
+		h.routes = append(h.routes, newRoute(desc.ServiceName, md.MethodName, md, nil))
+	}
+	for i := range desc.Streams {
+		sd := &desc.Streams[i]
+		h.routes = append(h.routes, newRoute(desc.ServiceName, sd.StreamName, nil, sd))
+	}
+	return h
+}
+
+// newRoute returns the route of a method. It panics if the method has no
+// HTTP rule as that is an error in the proto annotations.
+func newRoute(service, method string, unary *grpc.MethodDesc, stream *grpc.StreamDesc) route {
+	fullMethod := "/" + service + "/" + method
+	rule, err := lookupRule(fullMethod)
+	if err != nil {
+		panic(err)
+	}
+	r := route{method: fullMethod, rule: rule, unary: unary, stream: stream}
+	expr := "^"
+	last := 0
+	for _, m := range fieldRefRE.FindAllStringSubmatchIndex(rule.path, -1) {
+		expr += regexp.QuoteMeta(rule.path[last:m[0]])
+		r.fields = append(r.fields, rule.path[m[2]:m[3]])
+		if m[4] >= 0 && rule.path[m[4]:m[5]] == "=**" {
+			expr += "(.+)"
+		} else {
+			expr += "([^/]+)"
+		}
+		last = m[1]
+	}
+	r.pathRE = regexp.MustCompile(expr + regexp.QuoteMeta(rule.path[last:]) + "$")
+	return r
+}
+
+func (h *handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Docker-Distribution-API-Version", "registry/2.0")
+	var pathMatched bool
+	for _, rt := range h.routes {
+		m := rt.pathRE.FindStringSubmatch(r.URL.Path)
+		if m == nil {
+			continue
+		}
+		pathMatched = true
+		if rt.rule.method != r.Method {
+			continue
+		}
+		call := &call{route: rt, w: w, r: r, pathValues: m[1:]}
+		call.serve(h.srv)
+		return
+	}
+	if pathMatched {
+		writeError(w, status.Error(codes.Unimplemented, "method not allowed"))
+		return
+	}
+	writeError(w, newError(codes.NotFound, "NOT_FOUND", "not found"))
+}
+
+// call is an HTTP request for a route. It implements grpc.ServerStream
+// for streaming methods.
+type call struct {
+	route
+	w          http.ResponseWriter
+	r          *http.Request
+	pathValues []string
+	// req is the request message once it has been received.
+	req protoreflect.Message
+	// sent is set once the response status has been written.
+	sent bool
+	// status is the HTTP status of a successful response set by the
+	// method with an httpCodeHeader, or zero for successStatus.
+	status int
+}
+
+// streamedBodies are the methods whose bytes request body is not read into
+// the request message. The method reads it from bodyReader instead, so
+// that a blob upload is not held in memory.
+var streamedBodies = map[string]bool{
+	"/foxygoat.dreg.Registry/UploadBlobChunk":    true,
+	"/foxygoat.dreg.Registry/CompleteBlobUpload": true,
+}
+
+// bodyKey is the context key of the request body of a method in
+// streamedBodies.
+type bodyKey struct{}
+
+// bodyReader returns the request body of a method in streamedBodies
+// served over HTTP, or data, the body field of its request message, when
+// it is called otherwise.
+func bodyReader(ctx context.Context, data []byte) io.Reader {
+	if body, ok := ctx.Value(bodyKey{}).(io.Reader); ok {
+		return body
+	}
+	return bytes.NewReader(data)
+}
+
+// httpCodeHeader is the header metadata key a method sets with
+// grpc.SetHeader to give the HTTP status of its successful response, as in
+// grpc-gateway.
+const httpCodeHeader = "x-http-code"
+
+func (c *call) serve(srv interface{}) {
+	var err error
+	if c.unary != nil {
+		var resp interface{}
+		ctx := grpc.NewContextWithServerTransportStream(c.r.Context(), transportStream{c})
+		if streamedBodies[c.method] {
+			ctx = context.WithValue(ctx, bodyKey{}, io.Reader(c.r.Body))
+		}
+		resp, err = c.unary.Handler(srv, ctx, c.RecvMsg, nil)
+		if err == nil {
+			err = c.writeResponse(resp.(proto.Message), true)
+		}
+	} else {
+		err = c.stream.Handler(srv, c)
+		if err == nil && !c.sent {
+			c.w.WriteHeader(c.successStatus())
+		}
+	}
+	if err != nil && !c.sent {
+		writeError(c.w, err)
+	}
+}
+
+// RecvMsg decodes the request into m from its path, query parameters,
+// headers and body.
+func (c *call) RecvMsg(m interface{}) error {
+	msg := m.(proto.Message).ProtoReflect()
+	c.req = msg
+	used := map[string]bool{}
+	for i, name := range c.fields {
+		used[name] = true
+		if err := setField(msg, name, c.pathValues[i]); err != nil {
+			return err
+		}
+	}
+	for _, h := range c.rule.headers {
+		key, tmpl := cut(h, ":")
+		if err := readHeaderFields(msg, strings.TrimSpace(tmpl), c.r.Header.Get(strings.TrimSpace(key)), used); err != nil {
+			return err
+		}
+	}
+	if c.rule.body != "" && streamedBodies[c.method] {
+		used[c.rule.body] = true
+	} else if c.rule.body != "" {
+		b, err := io.ReadAll(c.r.Body)
+		if err != nil {
+			return err
+		}
+		if c.rule.body == "*" {
+			if err := protojson.Unmarshal(b, msg.Interface()); err != nil {
+				return status.Errorf(codes.InvalidArgument, "invalid request body: %v", err)
+			}
+			return nil
+		}
+		used[c.rule.body] = true
+		msg.Set(fieldByName(msg, c.rule.body), protoreflect.ValueOfBytes(b))
+	}
+	for key, vals := range c.r.URL.Query() {
+		fd := msg.Descriptor().Fields().ByName(protoreflect.Name(key))
+		if fd == nil || used[key] || fd.IsList() || fd.Kind() == protoreflect.MessageKind || len(vals) == 0 {
+			continue
+		}
+		if err := setField(msg, key, vals[0]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// readHeaderFields sets the fields of msg referenced in tmpl, a header
+// binding value such as "bytes={offset}-", from the request header value
+// val. Headers that do not match tmpl are ignored.
+func readHeaderFields(msg protoreflect.Message, tmpl, val string, used map[string]bool) error {
+	refs := fieldRefRE.FindAllStringSubmatchIndex(tmpl, -1)
+	if len(refs) == 0 || val == "" {
+		return nil
+	}
+	expr := "^"
+	last := 0
+	var names []string
+	for _, m := range refs {
+		expr += regexp.QuoteMeta(tmpl[last:m[0]]) + "(.*)"
+		names = append(names, tmpl[m[2]:m[3]])
+		last = m[1]
+	}
+	m := regexp.MustCompile(expr + regexp.QuoteMeta(tmpl[last:]) + "$").FindStringSubmatch(val)
+	if m == nil {
+		return nil
+	}
+	for i, name := range names {
+		used[name] = true
+		if err := setField(msg, name, m[i+1]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func setField(msg protoreflect.Message, name, val string) error {
+	fd := fieldByName(msg, name)
+	v, err := parseValue(fd, val)
+	if err != nil {
+		return status.Errorf(codes.InvalidArgument, "invalid %s %q: %v", name, val, err)
+	}
+	msg.Set(fd, v)
+	return nil
+}
+
+// SendMsg writes a message of a streaming response. The response headers
+// are written with the first message.
+func (c *call) SendMsg(m interface{}) error {
+	if err := c.writeResponse(m.(proto.Message), !c.sent); err != nil {
+		return err
+	}
+	if f, ok := c.w.(http.Flusher); ok {
+		f.Flush()
+	}
+	return nil
+}
+
+// writeResponse writes msg as the response body, preceded by the
+// response status and headers if writeHeader is true. Fields bound to
+// response headers are not written to a JSON body.
+func (c *call) writeResponse(msg proto.Message, writeHeader bool) error {
+	resp := msg.ProtoReflect()
+	var body []byte
+	fromHeaders := map[string]bool{}
+	for _, h := range c.rule.responseHeaders {
+		key, tmpl := cut(h, ":")
+		refs := map[string]bool{}
+		val := strings.TrimSpace(expandFields(tmpl, resp, refs, false))
+		for ref := range refs {
+			fromHeaders[ref] = true
+		}
+		if writeHeader && val != "" && !hasEmptyField(resp, refs) {
+			c.w.Header().Set(strings.TrimSpace(key), val)
+		}
+	}
+	switch {
+	case c.rule.responseBody != "":
+		body = resp.Get(fieldByName(resp, c.rule.responseBody)).Bytes()
+	case hasJSONBody(c.rule, c.req, resp):
+		jsonMsg := proto.Clone(msg).ProtoReflect()
+		for name := range fromHeaders {
+			jsonMsg.Clear(fieldByName(jsonMsg, name))
+		}
+		b, err := protojson.MarshalOptions{UseProtoNames: true}.Marshal(jsonMsg.Interface())
+		if err != nil {
+			return err
+		}
+		body = b
+		if writeHeader {
+			c.w.Header().Set("Content-Type", "application/json")
+		}
+	}
+	if writeHeader {
+		c.sent = true
+		c.w.WriteHeader(c.successStatus())
+	}
+	_, err := c.w.Write(body)
+	return err
+}
+
+// successStatus returns the HTTP status of a successful response, which
+// for the registry API depends on the request method unless the method set
+// it.
+func (c *call) successStatus() int {
+	if c.status != 0 {
+		return c.status
+	}
+	switch c.r.Method {
+	case http.MethodPost, http.MethodPatch, http.MethodDelete:
+		return http.StatusAccepted
+	case http.MethodPut:
+		return http.StatusCreated
+	}
+	return http.StatusOK
+}
+
+// SetHeader sets the HTTP status of a successful response from the
+// httpCodeHeader of md. Other header metadata is ignored.
+func (c *call) SetHeader(md metadata.MD) error {
+	vals := md.Get(httpCodeHeader)
+	if len(vals) == 0 {
+		return nil
+	}
+	code, err := strconv.Atoi(vals[0])
+	if err != nil || code < 200 || code > 299 {
+		return status.Errorf(codes.Internal, "invalid %s %q", httpCodeHeader, vals[0])
+	}
+	c.status = code
+	return nil
+}
+
+func (c *call) SendHeader(md metadata.MD) error { return c.SetHeader(md) }
+func (c *call) SetTrailer(metadata.MD)          {}
+func (c *call) Context() context.Context        { return c.r.Context() }
+
+// transportStream is the grpc.ServerTransportStream of a unary call, for
+// grpc.SetHeader.
+type transportStream struct {
+	c *call
+}
+
+func (t transportStream) Method() string                  { return t.c.method }
+func (t transportStream) SetHeader(md metadata.MD) error  { return t.c.SetHeader(md) }
+func (t transportStream) SendHeader(md metadata.MD) error { return t.c.SetHeader(md) }
+func (t transportStream) SetTrailer(metadata.MD) error    { return nil }
+
+// newError returns a gRPC status error with the registry error code code,
+// such as "MANIFEST_UNKNOWN", which writeError sends in the response.
+func newError(c codes.Code, code, format string, args ...interface{}) error {
+	st := status.New(c, fmt.Sprintf(format, args...))
+	if withInfo, err := st.WithDetails(&errdetails.ErrorInfo{Reason: code}); err == nil {
+		st = withInfo
+	}
+	return st.Err()
+}
+
+// writeError writes err as a registry error response.
+// https://docs.docker.com/registry/spec/api/#errors
+func writeError(w http.ResponseWriter, err error) {
+	st := status.Convert(err)
+	if errors.Is(err, context.Canceled) {
+		st = status.New(codes.Canceled, err.Error())
+	}
+	code := "UNKNOWN"
+	if st.Code() == codes.Unimplemented {
+		code = "UNSUPPORTED"
+	}
+	for _, d := range st.Details() {
+		if info, ok := d.(*errdetails.ErrorInfo); ok {
+			code = info.Reason
+		}
+	}
+	var re registryErrors
+	re.Errors = append(re.Errors, registryError{Code: code, Message: st.Message()})
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(httpStatus(st.Code()))
+	_ = json.NewEncoder(w).Encode(re)
+}
+
+// httpStatus maps a gRPC status code to an HTTP status code, the inverse
+// of httpStatusCode.
+func httpStatus(code codes.Code) int {
+	switch code {
+	case codes.InvalidArgument:
+		return http.StatusBadRequest
+	case codes.Unauthenticated:
+		return http.StatusUnauthorized
+	case codes.PermissionDenied:
+		return http.StatusForbidden
+	case codes.NotFound:
+		return http.StatusNotFound
+	case codes.Aborted, codes.AlreadyExists, codes.FailedPrecondition:
+		return http.StatusConflict
+	case codes.OutOfRange:
+		return http.StatusRequestedRangeNotSatisfiable
+	case codes.ResourceExhausted:
+		return http.StatusTooManyRequests
+	case codes.Unimplemented:
+		return http.StatusMethodNotAllowed
+	case codes.Unavailable:
+		return http.StatusServiceUnavailable
+	case codes.DeadlineExceeded:
+		return http.StatusGatewayTimeout
+	}
+	return http.StatusInternalServerError
+}
@@ -0,0 +1,134 @@
+package registry
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"strings"
+
+	"foxygo.at/dreg/pb"
+)
+
+// Iterator iterates over the pages of a paginated list of repositories or
+// tags, fetching each page when the items of the previous page have been
+// consumed:
+//
+//	it := client.TagIterator("library/alpine", 0)
+//	for it.Next(ctx) {
+//		fmt.Println(it.Value())
+//	}
+//	if err := it.Err(); err != nil {
+//		...
+//	}
+type Iterator struct {
+	// fetch returns a page of items after last and the last item for the
+	// next page, or "" if it is the last page.
+	fetch func(ctx context.Context, last string) (items []string, next string, err error)
+
+	items   []string
+	value   string
+	next    string
+	started bool
+	err     error
+}
+
+// Next advances to the next item, fetching the next page if needed, and
+// reports whether there is one. It returns false at the end of the list
+// or on error. A page that is empty or links back to itself, rather than
+// to the next page, is an error.
+func (it *Iterator) Next(ctx context.Context) bool {
+	for len(it.items) == 0 {
+		if it.err != nil || (it.started && it.next == "") {
+			return false
+		}
+		it.started = true
+		last := it.next
+		it.items, it.next, it.err = it.fetch(ctx, last)
+		if it.err == nil && it.next != "" && (len(it.items) == 0 || it.next == last) {
+			it.err = fmt.Errorf("registry pagination does not advance after %q", last)
+		}
+	}
+	it.value, it.items = it.items[0], it.items[1:]
+	return true
+}
+
+// Value returns the current item.
+func (it *Iterator) Value() string {
+	return it.value
+}
+
+// Err returns the error that ended the iteration, if any.
+func (it *Iterator) Err() error {
+	return it.err
+}
+
+// Collect returns the remaining items, up to limit if it is not zero.
+func (it *Iterator) Collect(ctx context.Context, limit int) ([]string, error) {
+	var result []string
+	for (limit == 0 || len(result) < limit) && it.Next(ctx) {
+		result = append(result, it.Value())
+	}
+	return result, it.Err()
+}
+
+// RepositoryIterator returns an iterator over the repositories in the
+// registry, requesting pageSize repositories at a time. A pageSize of zero
+// uses the registry's default.
+func (c *Client) RepositoryIterator(pageSize int32) *Iterator {
+	fetch := func(ctx context.Context, last string) ([]string, string, error) {
+		req := &pb.ListRepositoriesRequest{N: pageSize, Last: last}
+		resp, err := c.ListRepositories(ctx, req)
+		if err != nil {
+			return nil, "", err
+		}
+		return resp.Repositories, nextLast(resp.Link), nil
+	}
+	return &Iterator{fetch: fetch}
+}
+
+// Repositories returns all repositories in the registry.
+func (c *Client) Repositories(ctx context.Context) ([]string, error) {
+	return c.RepositoryIterator(0).Collect(ctx, 0)
+}
+
+// TagIterator returns an iterator over the tags of the named repository,
+// requesting pageSize tags at a time. A pageSize of zero uses the
+// registry's default.
+func (c *Client) TagIterator(name string, pageSize int32) *Iterator {
+	fetch := func(ctx context.Context, last string) ([]string, string, error) {
+		req := &pb.ListImageTagsRequest{Name: name, N: pageSize, Last: last}
+		resp, err := c.ListImageTags(ctx, req)
+		if err != nil {
+			return nil, "", err
+		}
+		return resp.Tags, nextLast(resp.Link), nil
+	}
+	return &Iterator{fetch: fetch}
+}
+
+// Tags returns all tags of the named repository.
+func (c *Client) Tags(ctx context.Context, name string) ([]string, error) {
+	return c.TagIterator(name, 0).Collect(ctx, 0)
+}
+
+// nextLast returns the value of the "last" query parameter of the next page
+// URL in an RFC 5988 Link header value, such as
+//
+//	</v2/_catalog?last=b&n=100>; rel="next"
+//
+// It returns an empty string if there is no next page.
+func nextLast(link string) string {
+	for _, l := range strings.Split(link, ",") {
+		target, params := cut(strings.TrimSpace(l), ";")
+		params = strings.NewReplacer(" ", "", `"`, "").Replace(params)
+		if !strings.Contains(params, "rel=next") {
+			continue
+		}
+		u, err := url.Parse(strings.Trim(target, "<>"))
+		if err != nil {
+			return ""
+		}
+		return u.Query().Get("last")
+	}
+	return ""
+}
@@ -0,0 +1,135 @@
+package registry
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestNextLast(t *testing.T) {
+	tests := map[string]string{
+		``: "",
+		`</v2/_catalog?last=b&n=100>; rel="next"`:                              "b",
+		`</v2/_catalog?n=100&last=b>;rel=next`:                                 "b",
+		`</v2/a/tags/list?last=v1.0%2Brc>; rel="next"`:                         "v1.0+rc",
+		`</v2/_catalog?last=a>; rel="prev", </v2/_catalog?last=c>; rel="next"`: "c",
+		`</v2/_catalog?last=a>; rel="prev"`:                                    "",
+		`</v2/_catalog?n=100>; rel="next"`:                                     "",
+		`<%zz>; rel="next"`:                                                    "",
+	}
+	for link, want := range tests {
+		if got := nextLast(link); got != want {
+			t.Errorf("nextLast(%q) = %q, want %q", link, got, want)
+		}
+	}
+}
+
+// pagedFetch returns an Iterator fetch function serving pages, keyed by
+// the last item of the previous page, with "" for the first page. Each
+// page is its items followed by the last item for the next page.
+func pagedFetch(pages map[string][]string) func(context.Context, string) ([]string, string, error) {
+	return func(_ context.Context, last string) ([]string, string, error) {
+		page, ok := pages[last]
+		if !ok {
+			return nil, "", fmt.Errorf("no page after %q", last)
+		}
+		return page[:len(page)-1], page[len(page)-1], nil
+	}
+}
+
+func TestIterator(t *testing.T) {
+	tests := []struct {
+		name    string
+		pages   map[string][]string
+		limit   int
+		want    []string
+		wantErr string
+	}{
+		{
+			name:  "single page",
+			pages: map[string][]string{"": {"a", "b", ""}},
+			want:  []string{"a", "b"},
+		},
+		{
+			name:  "empty",
+			pages: map[string][]string{"": {""}},
+		},
+		{
+			name:  "pages",
+			pages: map[string][]string{"": {"a", "b", "b"}, "b": {"c", "d", "d"}, "d": {"e", ""}},
+			want:  []string{"a", "b", "c", "d", "e"},
+		},
+		{
+			name:  "limit",
+			pages: map[string][]string{"": {"a", "b", "b"}, "b": {"c", "d", "d"}},
+			limit: 3,
+			want:  []string{"a", "b", "c"},
+		},
+		{
+			name:    "fetch error",
+			pages:   map[string][]string{"": {"a", "b", "b"}},
+			want:    []string{"a", "b"},
+			wantErr: `no page after "b"`,
+		},
+		{
+			name:    "link does not advance",
+			pages:   map[string][]string{"": {"a", "b", "b"}, "b": {"c", "b"}},
+			want:    []string{"a", "b", "c"},
+			wantErr: `does not advance after "b"`,
+		},
+		{
+			name:    "empty page with next link",
+			pages:   map[string][]string{"": {"a", "b", "b"}, "b": {"c"}},
+			want:    []string{"a", "b"},
+			wantErr: `does not advance after "b"`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			it := &Iterator{fetch: pagedFetch(tt.pages)}
+			got, err := it.Collect(context.Background(), tt.limit)
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("got %q, want %q", got, tt.want)
+			}
+			if tt.wantErr == "" && err != nil {
+				t.Errorf("got error %v", err)
+			}
+			if tt.wantErr != "" && (err == nil || !strings.Contains(err.Error(), tt.wantErr)) {
+				t.Errorf("got error %v, want %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestIteratorClient(t *testing.T) {
+	ts := newTestServer(t, NewMemStorage())
+	for _, name := range []string{"c", "a", "b/x"} {
+		ts.pushImage(name, "latest", "layer")
+	}
+	for _, tag := range []string{"v3", "v1", "v2"} {
+		ts.pushImage("a", tag, "layer")
+	}
+	rpc, err := NewRegistryClient(ts.url, WithRetries(0))
+	if err != nil {
+		t.Fatal(err)
+	}
+	client := New(rpc)
+	ctx := context.Background()
+
+	repos, err := client.RepositoryIterator(1).Collect(ctx, 0)
+	if want := []string{"a", "b/x", "c"}; err != nil || !reflect.DeepEqual(repos, want) {
+		t.Errorf("repositories: got %q, %v, want %q", repos, err, want)
+	}
+	tags, err := client.TagIterator("a", 2).Collect(ctx, 0)
+	if want := []string{"latest", "v1", "v2", "v3"}; err != nil || !reflect.DeepEqual(tags, want) {
+		t.Errorf("tags: got %q, %v, want %q", tags, err, want)
+	}
+	if _, err := client.Tags(ctx, "missing"); status.Code(err) != codes.NotFound {
+		t.Errorf("tags of missing repository: got %v, want NotFound", err)
+	}
+}
@@ -0,0 +1,261 @@
+package registry
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"mime"
+	"strings"
+
+	"foxygo.at/dreg/pb"
+	"google.golang.org/protobuf/encoding/protojson"
+)
+
+// Manifest media types.
+const (
+	MediaTypeDockerManifest     = "application/vnd.docker.distribution.manifest.v2+json"
+	MediaTypeDockerManifestList = "application/vnd.docker.distribution.manifest.list.v2+json"
+	MediaTypeOCIManifest        = "application/vnd.oci.image.manifest.v1+json"
+	MediaTypeOCIIndex           = "application/vnd.oci.image.index.v1+json"
+)
+
+// Manifest gets the manifest for reference (a tag or digest) in the named
+// repository and parses it into resp.Manifest. resp.Digest is computed
+// from the manifest if the registry does not send it, and otherwise
+// verified against the manifest, as is a digest reference.
+func (c *Client) Manifest(ctx context.Context, name, reference string) (*pb.GetManifestResponse, error) {
+	req := &pb.GetManifestRequest{Name: name, Reference: reference}
+	resp, err := c.GetManifest(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+	if resp.Digest == "" {
+		resp.Digest = SHA256Digest(resp.Raw)
+	} else if err := VerifyDigest(resp.Digest, resp.Raw); err != nil {
+		return nil, fmt.Errorf("%s@%s: registry sent digest %s: %w", name, reference, resp.Digest, err)
+	}
+	if strings.Contains(reference, ":") {
+		if err := VerifyDigest(reference, resp.Raw); err != nil {
+			return nil, fmt.Errorf("%s@%s: %w", name, reference, err)
+		}
+	}
+	m, err := ParseManifest(resp.MediaType, resp.Raw)
+	if err != nil {
+		return nil, fmt.Errorf("%s@%s: %w", name, reference, err)
+	}
+	resp.Manifest = m
+	return resp, nil
+}
+
+// Resolve returns the digest of the manifest that reference, a tag or
+// digest, refers to in the named repository.
+func (c *Client) Resolve(ctx context.Context, name, reference string) (string, error) {
+	req := &pb.GetDigestRequest{Name: name, Reference: reference}
+	resp, err := c.GetDigest(ctx, req)
+	if err != nil {
+		return "", err
+	}
+	if resp.Digest != "" {
+		if strings.Contains(reference, ":") && resp.Digest != reference {
+			return "", fmt.Errorf("%s@%s: registry sent digest %s", name, reference, resp.Digest)
+		}
+		return resp.Digest, nil
+	}
+	// The registry did not send a Docker-Content-Digest header.
+	m, err := c.Manifest(ctx, name, reference)
+	if err != nil {
+		return "", err
+	}
+	return m.Digest, nil
+}
+
+// Delete deletes the manifest or tag reference from the named repository.
+// It is not started if ctx is done, but once started it is not cancelled
+// with ctx so that it is known whether the image was deleted. Only the
+// request timeout limits it.
+func (c *Client) Delete(ctx context.Context, name, reference string) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	req := &pb.DeleteImageRequest{Name: name, Reference: reference}
+	_, err := c.DeleteImage(context.Background(), req)
+	return err
+}
+
+// Size returns the compressed size of the image that reference, a tag or
+// digest, refers to in the named repository: the sum of the sizes of its
+// config and layers. For an image index, it is the size of the images for
+// the platforms matching filter, counting blobs they share once. A nil
+// filter matches all platforms.
+func (c *Client) Size(ctx context.Context, name, reference string, filter *pb.Platform) (uint64, error) {
+	resp, err := c.Manifest(ctx, name, reference)
+	if err != nil {
+		return 0, err
+	}
+	images, err := c.Images(ctx, name, resp, filter)
+	if err != nil {
+		return 0, err
+	}
+	blobs := map[string]uint64{}
+	for _, pi := range images {
+		blobs[pi.Image.GetConfig().GetDigest()] = pi.Image.GetConfig().GetSize()
+		for _, layer := range pi.Image.Layers {
+			blobs[layer.Digest] = layer.Size
+		}
+	}
+	var size uint64
+	for _, s := range blobs {
+		size += s
+	}
+	return size, nil
+}
+
+// ParseManifest parses the raw bytes of an image manifest or image index.
+// The media type of the manifest is taken from the Content-Type of the
+// response if it is a manifest media type, otherwise from the mediaType
+// field of the manifest.
+func ParseManifest(contentType string, raw []byte) (*pb.Manifest, error) {
+	var probe struct {
+		MediaType string          `json:"mediaType"`
+		Manifests json.RawMessage `json:"manifests"`
+	}
+	if err := json.Unmarshal(raw, &probe); err != nil {
+		return nil, fmt.Errorf("cannot parse manifest: %w", err)
+	}
+	mediaType, _, _ := mime.ParseMediaType(contentType)
+	if !IsManifestMediaType(mediaType) {
+		mediaType = probe.MediaType
+	}
+	if mediaType == "" {
+		// The mediaType field is optional in OCI manifests.
+		mediaType = MediaTypeOCIManifest
+		if probe.Manifests != nil {
+			mediaType = MediaTypeOCIIndex
+		}
+	}
+
+	opts := protojson.UnmarshalOptions{DiscardUnknown: true}
+	switch mediaType {
+	case MediaTypeDockerManifest, MediaTypeOCIManifest:
+		image := &pb.ImageManifest{}
+		if err := opts.Unmarshal(raw, image); err != nil {
+			return nil, fmt.Errorf("cannot parse image manifest: %w", err)
+		}
+		return &pb.Manifest{Manifest: &pb.Manifest_Image{Image: image}}, nil
+	case MediaTypeDockerManifestList, MediaTypeOCIIndex:
+		index := &pb.ImageIndex{}
+		if err := opts.Unmarshal(raw, index); err != nil {
+			return nil, fmt.Errorf("cannot parse image index: %w", err)
+		}
+		return &pb.Manifest{Manifest: &pb.Manifest_Index{Index: index}}, nil
+	}
+	return nil, fmt.Errorf("unsupported manifest media type %q", mediaType)
+}
+
+func IsManifestMediaType(mediaType string) bool {
+	switch mediaType {
+	case MediaTypeDockerManifest, MediaTypeDockerManifestList, MediaTypeOCIManifest, MediaTypeOCIIndex:
+		return true
+	}
+	return false
+}
+
+// ManifestMediaType returns the media type of m from its mediaType field,
+// which is optional for OCI manifests.
+func ManifestMediaType(m *pb.Manifest) string {
+	switch m := m.Manifest.(type) {
+	case *pb.Manifest_Image:
+		if m.Image.MediaType != "" {
+			return m.Image.MediaType
+		}
+		return MediaTypeOCIManifest
+	case *pb.Manifest_Index:
+		if m.Index.MediaType != "" {
+			return m.Index.MediaType
+		}
+		return MediaTypeOCIIndex
+	}
+	return ""
+}
+
+// PlatformImage is an image manifest and the platform it is for. Platform
+// is nil if the image is not referenced from an image index and its
+// platform was not needed to filter it.
+type PlatformImage struct {
+	Platform  *pb.Platform
+	Digest    string
+	MediaType string
+	Raw       []byte
+	Image     *pb.ImageManifest
+}
+
+// Images returns the images of the manifest in resp from the named
+// repository. For an image index, it fetches the image manifests for the
+// platforms in the index that match filter. A nil filter matches all
+// platforms. An image manifest is returned as is for a nil filter, and
+// otherwise only if the platform in its config matches filter.
+func (c *Client) Images(ctx context.Context, name string, resp *pb.GetManifestResponse, filter *pb.Platform) ([]PlatformImage, error) {
+	if image := resp.Manifest.GetImage(); image != nil {
+		digest := resp.Digest
+		if digest == "" {
+			digest = SHA256Digest(resp.Raw)
+		}
+		pi := PlatformImage{Digest: digest, MediaType: resp.MediaType, Raw: resp.Raw, Image: image}
+		if filter != nil {
+			config, _, err := c.ImageConfig(ctx, name, image)
+			if err != nil {
+				return nil, fmt.Errorf("%s@%s: %w", name, digest, err)
+			}
+			pi.Platform = &pb.Platform{Os: config.Os, Architecture: config.Architecture, Variant: config.Variant}
+			if !PlatformMatches(filter, pi.Platform) {
+				return nil, nil
+			}
+		}
+		return []PlatformImage{pi}, nil
+	}
+	var result []PlatformImage
+	for _, desc := range resp.Manifest.GetIndex().GetManifests() {
+		if !isImagePlatform(desc.Platform) || !PlatformMatches(filter, desc.Platform) {
+			continue
+		}
+		child, err := c.Manifest(ctx, name, desc.Digest)
+		if err != nil {
+			return nil, err
+		}
+		// Nested indexes are not followed.
+		if image := child.Manifest.GetImage(); image != nil {
+			pi := PlatformImage{
+				Platform:  desc.Platform,
+				Digest:    desc.Digest,
+				MediaType: child.MediaType,
+				Raw:       child.Raw,
+				Image:     image,
+			}
+			result = append(result, pi)
+		}
+	}
+	return result, nil
+}
+
+// ImageConfig fetches and parses the config of image in the named
+// repository, returning it with its raw bytes.
+func (c *Client) ImageConfig(ctx context.Context, name string, image *pb.ImageManifest) (*pb.ImageConfig, []byte, error) {
+	raw, err := c.ReadBlob(ctx, name, image.GetConfig().GetDigest())
+	if err != nil {
+		return nil, nil, err
+	}
+	config := &pb.ImageConfig{}
+	if err := (protojson.UnmarshalOptions{DiscardUnknown: true}).Unmarshal(raw, config); err != nil {
+		return nil, nil, fmt.Errorf("cannot parse image config: %w", err)
+	}
+	return config, raw, nil
+}
+
+// LayerSize returns the sum of the layer sizes of image.
+func LayerSize(image *pb.ImageManifest) uint64 {
+	var size uint64
+	for _, layer := range image.Layers {
+		size += layer.Size
+	}
+	return size
+}
@@ -0,0 +1,54 @@
+package registry
+
+import (
+	"fmt"
+	"strings"
+
+	"foxygo.at/dreg/pb"
+)
+
+// ParsePlatform parses a platform of the form "os/arch[/variant]", such as
+// "linux/arm64/v8".
+func ParsePlatform(s string) (*pb.Platform, error) {
+	parts := strings.Split(s, "/")
+	if len(parts) < 2 || len(parts) > 3 || parts[0] == "" || parts[1] == "" {
+		return nil, fmt.Errorf("invalid platform %q: must be os/arch[/variant]", s)
+	}
+	p := &pb.Platform{Os: parts[0], Architecture: parts[1]}
+	if len(parts) == 3 {
+		p.Variant = parts[2]
+	}
+	return p, nil
+}
+
+// FormatPlatform formats p as "os/arch[/variant]". It returns an empty
+// string for a nil platform.
+func FormatPlatform(p *pb.Platform) string {
+	if p == nil {
+		return ""
+	}
+	s := p.Os + "/" + p.Architecture
+	if p.Variant != "" {
+		s += "/" + p.Variant
+	}
+	return s
+}
+
+// PlatformMatches returns true if p matches filter. A nil filter matches
+// any platform. The variant is only compared if filter specifies one.
+func PlatformMatches(filter, p *pb.Platform) bool {
+	if filter == nil {
+		return true
+	}
+	if p == nil || p.Os != filter.Os || p.Architecture != filter.Architecture {
+		return false
+	}
+	return filter.Variant == "" || p.Variant == filter.Variant
+}
+
+// isImagePlatform returns false for manifests in an image index that are
+// not runnable images, such as buildkit attestations which use the
+// platform "unknown/unknown".
+func isImagePlatform(p *pb.Platform) bool {
+	return p.GetOs() != "unknown" && p.GetArchitecture() != "unknown"
+}
@@ -0,0 +1,307 @@
+package registry
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"io/fs"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"foxygo.at/dreg/pb"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// Proxy is a pull-through caching registry server. Manifests and blobs
+// are fetched from an upstream registry when they are first pulled and
+// stored in a Storage, from which later pulls are served. Tags are
+// revalidated with the upstream registry when they were last checked
+// longer than the TTL ago, using a HEAD request so that only changed
+// manifests are fetched. If the upstream registry cannot be reached, the
+// stored tag is served.
+//
+// Repositories are listed from the Storage and tags from the upstream
+// registry. Pushing and deleting are not supported.
+//
+// A blob is streamed to the client that first pulls it as it is fetched
+// and stored. Concurrent pulls of the blob wait for the fetch and are
+// served from the Storage. The fetch is not cancelled if the client that
+// started it goes away, so that it completes for the others.
+//
+// If DockerHub is set, single component repository names such as
+// "alpine" refer to the official "library/alpine" repository, as they do
+// for docker.
+//
+// Authentication with the upstream registry is up to the upstream Client.
+// The Proxy itself does not authenticate its clients.
+type Proxy struct {
+	pb.UnimplementedRegistryServer
+	// DockerHub is set if the upstream registry is Docker Hub.
+	DockerHub bool
+
+	local    *Server
+	upstream *Client
+	ttl      time.Duration
+
+	mu sync.Mutex
+	// checked is when each tag, as "name:tag", was last checked with
+	// the upstream registry.
+	checked map[string]time.Time
+	// fetches are closed when the blob fetch in progress for their
+	// digest is done.
+	fetches map[string]chan struct{}
+}
+
+// NewProxy returns a Proxy for upstream storing manifests and blobs in
+// storage and revalidating tags after ttl.
+func NewProxy(upstream *Client, storage Storage, ttl time.Duration) *Proxy {
+	return &Proxy{
+		local:    NewServer(storage),
+		upstream: upstream,
+		ttl:      ttl,
+		checked:  map[string]time.Time{},
+		fetches:  map[string]chan struct{}{},
+	}
+}
+
+// repoName returns the upstream repository name for name, which is
+// prefixed with "library/" if it is a single component Docker Hub name.
+func (p *Proxy) repoName(name string) string {
+	if p.DockerHub && !strings.Contains(name, "/") {
+		return "library/" + name
+	}
+	return name
+}
+
+func (p *Proxy) CheckV2(ctx context.Context, req *pb.CheckV2Request) (*pb.CheckV2Response, error) {
+	return p.local.CheckV2(ctx, req)
+}
+
+func (p *Proxy) ListRepositories(ctx context.Context, req *pb.ListRepositoriesRequest) (*pb.ListRepositoriesResponse, error) {
+	return p.local.ListRepositories(ctx, req)
+}
+
+func (p *Proxy) ListImageTags(ctx context.Context, req *pb.ListImageTagsRequest) (*pb.ListImageTagsResponse, error) {
+	if err := checkName(req.Name); err != nil {
+		return nil, err
+	}
+	upstreamReq := &pb.ListImageTagsRequest{Name: p.repoName(req.Name), N: req.N, Last: req.Last}
+	resp, err := p.upstream.ListImageTags(ctx, upstreamReq)
+	if err != nil {
+		return nil, err
+	}
+	resp.Name = req.Name
+	if last := nextLast(resp.Link); last != "" {
+		// Point the link at this registry.
+		q := url.Values{"last": {last}}
+		if req.N > 0 {
+			q.Set("n", strconv.Itoa(int(req.N)))
+		}
+		resp.Link = fmt.Sprintf(`</v2/%s/tags/list?%s>; rel="next"`, req.Name, q.Encode())
+	}
+	return resp, nil
+}
+
+func (p *Proxy) GetDigest(ctx context.Context, req *pb.GetDigestRequest) (*pb.GetDigestResponse, error) {
+	req = &pb.GetDigestRequest{Name: p.repoName(req.Name), Reference: req.Reference}
+	if err := p.fetchManifest(ctx, req.Name, req.Reference); err != nil {
+		return nil, err
+	}
+	return p.local.GetDigest(ctx, req)
+}
+
+func (p *Proxy) GetManifest(ctx context.Context, req *pb.GetManifestRequest) (*pb.GetManifestResponse, error) {
+	req = &pb.GetManifestRequest{Name: p.repoName(req.Name), Reference: req.Reference}
+	if err := p.fetchManifest(ctx, req.Name, req.Reference); err != nil {
+		return nil, err
+	}
+	return p.local.GetManifest(ctx, req)
+}
+
+// fetchManifest stores the manifest that reference, a tag or digest,
+// refers to in the upstream repository unless it is stored already and,
+// for a tag, was checked within the TTL.
+func (p *Proxy) fetchManifest(ctx context.Context, name, reference string) error {
+	if err := checkName(name); err != nil {
+		return err
+	}
+	storage := p.local.storage
+	if digestRE.MatchString(reference) {
+		return p.fetchManifestDigest(ctx, name, reference)
+	}
+	if !tagRE.MatchString(reference) {
+		return newError(codes.InvalidArgument, "TAG_INVALID", "invalid tag or digest %q", reference)
+	}
+
+	key := name + ":" + reference
+	local, err := storage.GetTag(name, reference)
+	p.mu.Lock()
+	fresh := err == nil && time.Since(p.checked[key]) < p.ttl
+	p.mu.Unlock()
+	if fresh {
+		return nil
+	}
+	digest, err := p.upstream.Resolve(ctx, name, reference)
+	if err != nil {
+		if local != "" && status.Code(err) != codes.NotFound {
+			// Serve the stored tag while upstream is unavailable.
+			return nil
+		}
+		return upstreamError(err, manifestError(fs.ErrNotExist, name, reference))
+	}
+	if digest != local {
+		if err := p.fetchManifestDigest(ctx, name, digest); err != nil {
+			return err
+		}
+		if err := storage.PutTag(name, reference, digest); err != nil {
+			return storageError(err)
+		}
+	}
+	p.mu.Lock()
+	p.checked[key] = time.Now()
+	p.mu.Unlock()
+	return nil
+}
+
+func (p *Proxy) fetchManifestDigest(ctx context.Context, name, digest string) error {
+	storage := p.local.storage
+	if _, _, err := storage.GetManifest(name, digest); err == nil {
+		return nil
+	}
+	resp, err := p.upstream.Manifest(ctx, name, digest)
+	if err != nil {
+		return upstreamError(err, manifestError(fs.ErrNotExist, name, digest))
+	}
+	if err := VerifyDigest(digest, resp.Raw); err != nil {
+		return status.Errorf(codes.Unavailable, "upstream manifest %s@%s: %v", name, digest, err)
+	}
+	mediaType := resp.MediaType
+	if !IsManifestMediaType(mediaType) {
+		mediaType = ManifestMediaType(resp.Manifest)
+	}
+	if err := storage.PutManifest(name, digest, mediaType, resp.Raw); err != nil {
+		return storageError(err)
+	}
+	return nil
+}
+
+// HeadBlob answers from the Storage if the blob is stored, and otherwise
+// from the upstream registry without fetching the blob.
+func (p *Proxy) HeadBlob(ctx context.Context, req *pb.HeadBlobRequest) (*pb.HeadBlobResponse, error) {
+	req = &pb.HeadBlobRequest{Name: p.repoName(req.Name), Digest: req.Digest}
+	if resp, err := p.local.HeadBlob(ctx, req); status.Code(err) != codes.NotFound {
+		return resp, err
+	}
+	resp, err := p.upstream.HeadBlob(ctx, req)
+	if err != nil {
+		return nil, upstreamError(err, blobError(fs.ErrNotExist, req.Name, req.Digest))
+	}
+	return resp, nil
+}
+
+// GetBlob serves the blob from the Storage, fetching it from the upstream
+// registry first if it is not stored. A request from the start of the
+// blob is sent the blob as it is fetched.
+func (p *Proxy) GetBlob(req *pb.GetBlobRequest, stream pb.Registry_GetBlobServer) error {
+	req = &pb.GetBlobRequest{Name: p.repoName(req.Name), Digest: req.Digest, Offset: req.Offset}
+	if err := checkBlob(req.Name, req.Digest); err != nil {
+		return err
+	}
+	var w *blobStreamWriter
+	if req.Offset == 0 {
+		w = &blobStreamWriter{stream: stream}
+	}
+	if err := p.fetchBlob(stream.Context(), req.Name, req.Digest, w); err != nil {
+		return err
+	}
+	if w != nil && (w.err != nil || w.n > 0) {
+		return w.err
+	}
+	return p.local.GetBlob(req, stream)
+}
+
+// fetchBlob stores the blob with the given digest from the upstream
+// repository unless it is stored already, writing it to w as it is
+// fetched if w is not nil. The blob is verified against its digest as it
+// is stored, so it may have been written to w in part or in full when it
+// does not match. If the blob is being fetched already, fetchBlob waits
+// for that fetch instead, or until ctx is done.
+//
+// The fetch itself is not cancelled with ctx as other requests may be
+// waiting for it. If writing to w fails, the blob is still stored.
+func (p *Proxy) fetchBlob(ctx context.Context, name, digest string, w *blobStreamWriter) error {
+	storage := p.local.storage
+	for {
+		if _, err := storage.StatBlob(name, digest); err == nil {
+			return nil
+		}
+		p.mu.Lock()
+		fetch, ok := p.fetches[digest]
+		if !ok {
+			p.fetches[digest] = make(chan struct{})
+		}
+		p.mu.Unlock()
+		if !ok {
+			break
+		}
+		select {
+		case <-fetch:
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+	defer func() {
+		p.mu.Lock()
+		close(p.fetches[digest])
+		delete(p.fetches, digest)
+		p.mu.Unlock()
+	}()
+
+	r, err := p.upstream.OpenBlob(context.Background(), name, digest, 0)
+	if err != nil {
+		return upstreamError(err, blobError(fs.ErrNotExist, name, digest))
+	}
+	if w != nil {
+		r = io.TeeReader(r, w)
+	}
+	if err := storage.PutBlob(name, digest, VerifyingReader(r, digest)); err != nil {
+		if _, ok := status.FromError(err); ok {
+			return upstreamError(err, blobError(fs.ErrNotExist, name, digest))
+		}
+		return status.Errorf(codes.Unavailable, "upstream blob %s@%s: %v", name, digest, err)
+	}
+	return nil
+}
+
+// blobStreamWriter writes to a GetBlob stream, counting the bytes sent.
+// Once sending fails, it records the error and discards further writes.
+type blobStreamWriter struct {
+	stream pb.Registry_GetBlobServer
+	n      int64
+	err    error
+}
+
+func (w *blobStreamWriter) Write(p []byte) (int, error) {
+	if w.err != nil {
+		return len(p), nil
+	}
+	if err := w.stream.Send(&pb.GetBlobResponse{Data: p}); err != nil {
+		w.err = err
+		return len(p), nil
+	}
+	w.n += int64(len(p))
+	return len(p), nil
+}
+
+// upstreamError returns notFound if err is a NotFound error from the
+// upstream registry, and err otherwise.
+func upstreamError(err, notFound error) error {
+	if status.Code(err) == codes.NotFound {
+		return notFound
+	}
+	return err
+}
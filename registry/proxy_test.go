@@ -0,0 +1,264 @@
+package registry
+
+import (
+	"bytes"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// testUpstream is a registry server for a Proxy to fetch from. It counts
+// the requests it receives, can be taken down and can hold blob requests
+// until they are released.
+type testUpstream struct {
+	*testServer
+	handler http.Handler
+
+	mu       sync.Mutex
+	requests map[string]int
+	down     bool
+	// release, if not nil, holds blob GET requests until it is closed.
+	// requested is sent each blob GET request that is held.
+	release   chan struct{}
+	requested chan struct{}
+}
+
+func (u *testUpstream) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	u.mu.Lock()
+	u.requests[r.Method+" "+r.URL.Path]++
+	down, release := u.down, u.release
+	u.mu.Unlock()
+	if down {
+		http.Error(w, "down", http.StatusServiceUnavailable)
+		return
+	}
+	if release != nil && r.Method == "GET" && strings.Contains(r.URL.Path, "/blobs/") {
+		u.requested <- struct{}{}
+		<-release
+	}
+	u.handler.ServeHTTP(w, r)
+}
+
+// count returns the number of requests for method and path.
+func (u *testUpstream) count(method, path string) int {
+	u.mu.Lock()
+	defer u.mu.Unlock()
+	return u.requests[method+" "+path]
+}
+
+// newTestProxy returns a Proxy with the given TTL served over HTTP and the
+// upstream registry it fetches from.
+func newTestProxy(t *testing.T, ttl time.Duration) (*testServer, *Proxy, *testUpstream) {
+	t.Helper()
+	srv := NewServer(NewMemStorage())
+	upstream := &testUpstream{handler: NewHandler(srv), requests: map[string]int{}}
+	uhs := httptest.NewServer(upstream)
+	t.Cleanup(uhs.Close)
+	upstream.testServer = &testServer{t: t, srv: srv, url: uhs.URL}
+
+	rpc, err := NewRegistryClient(uhs.URL, WithRetries(0))
+	if err != nil {
+		t.Fatal(err)
+	}
+	proxy := NewProxy(New(rpc), NewMemStorage(), ttl)
+	phs := httptest.NewServer(NewHandler(proxy))
+	t.Cleanup(phs.Close)
+	return &testServer{t: t, url: phs.URL}, proxy, upstream
+}
+
+// expire marks the tags of proxy as last checked longer than ttl ago.
+func expire(proxy *Proxy, ttl time.Duration) {
+	proxy.mu.Lock()
+	for key := range proxy.checked {
+		proxy.checked[key] = time.Now().Add(-2 * ttl)
+	}
+	proxy.mu.Unlock()
+}
+
+func TestProxyPull(t *testing.T) {
+	ts, _, upstream := newTestProxy(t, time.Hour)
+	manifest, digest := upstream.pushImage("a", "latest", "layer")
+	layerDigest := SHA256Digest([]byte("layer"))
+
+	for i := 0; i < 2; i++ {
+		resp, b := ts.expect(http.StatusOK, "GET", "/v2/a/manifests/latest", nil)
+		if !bytes.Equal(b, manifest) {
+			t.Errorf("GET manifest: got %s, want %s", b, manifest)
+		}
+		if got := resp.Header.Get("Docker-Content-Digest"); got != digest {
+			t.Errorf("GET manifest: got digest %q, want %q", got, digest)
+		}
+		_, b = ts.expect(http.StatusOK, "GET", "/v2/a/blobs/"+layerDigest, nil)
+		if string(b) != "layer" {
+			t.Errorf("GET blob: got %q, want %q", b, "layer")
+		}
+	}
+	if n := upstream.count("GET", "/v2/a/manifests/"+digest); n != 1 {
+		t.Errorf("got %d upstream manifest fetches, want 1", n)
+	}
+	if n := upstream.count("GET", "/v2/a/blobs/"+layerDigest); n != 1 {
+		t.Errorf("got %d upstream blob fetches, want 1", n)
+	}
+	ts.expect(http.StatusNotFound, "GET", "/v2/a/manifests/missing", nil)
+	ts.expect(http.StatusNotFound, "GET", "/v2/a/blobs/"+SHA256Digest([]byte("missing")), nil)
+}
+
+func TestProxyRevalidate(t *testing.T) {
+	ttl := time.Hour
+	ts, proxy, upstream := newTestProxy(t, ttl)
+	_, digest1 := upstream.pushImage("a", "latest", "layer one")
+	_, digest2 := upstream.pushImage("a", "latest", "layer two")
+	upstream.pushImage("a", "latest", "layer one")
+
+	resp, _ := ts.expect(http.StatusOK, "GET", "/v2/a/manifests/latest", nil)
+	if got := resp.Header.Get("Docker-Content-Digest"); got != digest1 {
+		t.Fatalf("got digest %q, want %q", got, digest1)
+	}
+	// The tag is not checked with upstream within the TTL.
+	upstream.pushImage("a", "latest", "layer two")
+	resp, _ = ts.expect(http.StatusOK, "GET", "/v2/a/manifests/latest", nil)
+	if got := resp.Header.Get("Docker-Content-Digest"); got != digest1 {
+		t.Errorf("within TTL: got digest %q, want %q", got, digest1)
+	}
+	if n := upstream.count("HEAD", "/v2/a/manifests/latest"); n != 1 {
+		t.Errorf("within TTL: got %d upstream checks, want 1", n)
+	}
+
+	expire(proxy, ttl)
+	resp, _ = ts.expect(http.StatusOK, "GET", "/v2/a/manifests/latest", nil)
+	if got := resp.Header.Get("Docker-Content-Digest"); got != digest2 {
+		t.Errorf("after TTL: got digest %q, want %q", got, digest2)
+	}
+	if n := upstream.count("HEAD", "/v2/a/manifests/latest"); n != 2 {
+		t.Errorf("after TTL: got %d upstream checks, want 2", n)
+	}
+	// A tag that is unchanged upstream is not fetched again.
+	expire(proxy, ttl)
+	ts.expect(http.StatusOK, "GET", "/v2/a/manifests/latest", nil)
+	if n := upstream.count("GET", "/v2/a/manifests/"+digest2); n != 1 {
+		t.Errorf("unchanged: got %d upstream manifest fetches, want 1", n)
+	}
+}
+
+func TestProxyUpstreamDown(t *testing.T) {
+	ttl := time.Hour
+	ts, proxy, upstream := newTestProxy(t, ttl)
+	manifest, _ := upstream.pushImage("a", "latest", "layer")
+	upstream.pushImage("a", "other", "other layer")
+	ts.expect(http.StatusOK, "GET", "/v2/a/manifests/latest", nil)
+
+	upstream.mu.Lock()
+	upstream.down = true
+	upstream.mu.Unlock()
+	expire(proxy, ttl)
+	_, b := ts.expect(http.StatusOK, "GET", "/v2/a/manifests/latest", nil)
+	if !bytes.Equal(b, manifest) {
+		t.Errorf("got %s, want %s", b, manifest)
+	}
+	ts.expect(http.StatusServiceUnavailable, "GET", "/v2/a/manifests/other", nil)
+}
+
+func TestProxyConcurrentFetch(t *testing.T) {
+	ts, proxy, upstream := newTestProxy(t, time.Hour)
+	data := bytes.Repeat([]byte("blob data "), 10000)
+	digest := upstream.pushBlob("a", data)
+	upstream.mu.Lock()
+	upstream.release = make(chan struct{})
+	upstream.requested = make(chan struct{}, 1)
+	upstream.mu.Unlock()
+
+	// The first request starts the fetch and goes away before it is done.
+	ctx, cancel := context.WithCancel(context.Background())
+	req, err := http.NewRequestWithContext(ctx, "GET", ts.url+"/v2/a/blobs/"+digest, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	firstDone := make(chan struct{})
+	go func() {
+		defer close(firstDone)
+		if resp, err := http.DefaultClient.Do(req); err == nil {
+			resp.Body.Close()
+		}
+	}()
+	<-upstream.requested
+
+	var wg sync.WaitGroup
+	bodies := make([][]byte, 5)
+	for i := range bodies {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			_, bodies[i] = ts.do("GET", "/v2/a/blobs/"+digest, nil)
+		}(i)
+	}
+	// Give the requests time to wait for the fetch in progress.
+	time.Sleep(50 * time.Millisecond)
+	cancel()
+	<-firstDone
+	close(upstream.release)
+	wg.Wait()
+
+	for i, b := range bodies {
+		if !bytes.Equal(b, data) {
+			t.Errorf("request %d: got %d bytes, want %d", i, len(b), len(data))
+		}
+	}
+	if n := upstream.count("GET", "/v2/a/blobs/"+digest); n != 1 {
+		t.Errorf("got %d upstream blob fetches, want 1", n)
+	}
+	if _, err := proxy.local.storage.StatBlob("a", digest); err != nil {
+		t.Errorf("blob not stored: %v", err)
+	}
+}
+
+func TestProxyDockerHub(t *testing.T) {
+	ts, proxy, upstream := newTestProxy(t, time.Hour)
+	proxy.DockerHub = true
+	manifest, _ := upstream.pushImage("library/alpine", "latest", "layer")
+	upstream.pushImage("user/alpine", "latest", "user layer")
+	layerDigest := SHA256Digest([]byte("layer"))
+
+	_, b := ts.expect(http.StatusOK, "GET", "/v2/alpine/manifests/latest", nil)
+	if !bytes.Equal(b, manifest) {
+		t.Errorf("GET manifest: got %s, want %s", b, manifest)
+	}
+	_, b = ts.expect(http.StatusOK, "GET", "/v2/alpine/blobs/"+layerDigest, nil)
+	if string(b) != "layer" {
+		t.Errorf("GET blob: got %q, want %q", b, "layer")
+	}
+	ts.expect(http.StatusOK, "GET", "/v2/library/alpine/manifests/latest", nil)
+	ts.expect(http.StatusOK, "GET", "/v2/user/alpine/manifests/latest", nil)
+	if n := upstream.count("HEAD", "/v2/alpine/manifests/latest"); n != 0 {
+		t.Errorf("got %d upstream requests for alpine, want 0", n)
+	}
+}
+
+func TestProxyDigestMismatch(t *testing.T) {
+	ts, proxy, upstream := newTestProxy(t, time.Hour)
+	storage := upstream.srv.storage
+	blobDigest := SHA256Digest([]byte("blob"))
+	if err := storage.PutBlob("a", blobDigest, strings.NewReader("not the blob")); err != nil {
+		t.Fatal(err)
+	}
+	manifestDigest := SHA256Digest([]byte("{}"))
+	if err := storage.PutManifest("a", manifestDigest, MediaTypeOCIManifest, []byte(`{"schemaVersion":2}`)); err != nil {
+		t.Fatal(err)
+	}
+
+	// The blob is streamed before it is verified, so the response may
+	// have been sent. It must not be stored.
+	ts.do("GET", "/v2/a/blobs/"+blobDigest, nil)
+	if _, err := proxy.local.storage.StatBlob("a", blobDigest); err == nil {
+		t.Error("blob with mismatched digest stored")
+	}
+	if resp, _ := ts.do("GET", "/v2/a/manifests/"+manifestDigest, nil); resp.StatusCode == http.StatusOK {
+		t.Error("manifest with mismatched digest served")
+	}
+	if _, _, err := proxy.local.storage.GetManifest("a", manifestDigest); err == nil {
+		t.Error("manifest with mismatched digest stored")
+	}
+}
@@ -0,0 +1,103 @@
+// Package registry is a client for container image registries that
+// implement the Docker Registry HTTP API V2 or the OCI distribution spec.
+//
+// A Client adds typed methods for listing repositories and tags, resolving
+// and fetching manifests, reading and pushing blobs and deleting images to
+// a pb.RegistryClient, which it embeds for access to the underlying RPCs.
+// Dial returns a Client for a registry URL that authenticates with the
+// credentials in a docker config and retries transient errors.
+//
+// Server is a registry server implementing pb.RegistryServer, storing
+// images in memory or a directory, and Proxy is a pull-through cache of
+// another registry. NewHandler serves either over HTTP.
+package registry
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"time"
+
+	"foxygo.at/dreg/pb"
+)
+
+// DefaultRetries is the number of times Dial retries requests that fail
+// with a transient error unless WithRetries is given.
+const DefaultRetries = 3
+
+// Client is a registry client with typed methods over the RPCs of
+// pb.RegistryClient.
+type Client struct {
+	pb.RegistryClient
+}
+
+// New returns a Client making requests with rpc.
+func New(rpc pb.RegistryClient) *Client {
+	return &Client{RegistryClient: rpc}
+}
+
+// Option is an option for Dial and NewRegistryClient.
+type Option func(*options)
+
+type options struct {
+	dockerConfig DockerConfig
+	retries      int
+	timeout      time.Duration
+	log          io.Writer
+}
+
+// WithDockerConfig authenticates with the credentials for the registry
+// host in the docker config d.
+func WithDockerConfig(d DockerConfig) Option {
+	return func(o *options) { o.dockerConfig = d }
+}
+
+// WithRetries sets the number of times idempotent requests that fail with
+// a transient error are retried.
+func WithRetries(n int) Option {
+	return func(o *options) { o.retries = n }
+}
+
+// WithTimeout limits each request to d. Zero means no limit.
+func WithTimeout(d time.Duration) Option {
+	return func(o *options) { o.timeout = d }
+}
+
+// WithLog logs retries to w.
+func WithLog(w io.Writer) Option {
+	return func(o *options) { o.log = w }
+}
+
+// Dial returns a Client for the registry at registryURL, such as
+// "https://registry-1.docker.io".
+func Dial(registryURL string, opts ...Option) (*Client, error) {
+	rpc, err := NewRegistryClient(registryURL, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return New(rpc), nil
+}
+
+// NewRegistryClient returns a pb.RegistryClient for the registry at
+// registryURL that makes HTTP requests according to the google.api.http
+// annotations of the Registry service.
+func NewRegistryClient(registryURL string, opts ...Option) (pb.RegistryClient, error) {
+	o := options{retries: DefaultRetries}
+	for _, opt := range opts {
+		opt(&o)
+	}
+	u, err := url.Parse(registryURL)
+	if err != nil {
+		return nil, fmt.Errorf("invalid registry URL %q: %w", registryURL, err)
+	}
+	getCreds := func() (credentials, error) { return o.dockerConfig.credentials(u.Host) }
+	httpClient := &http.Client{Transport: newAuthTransport(u.Host, getCreds)}
+	conn := &retryConn{
+		next:    newRawConn(registryURL, httpClient),
+		retries: o.retries,
+		timeout: o.timeout,
+		log:     o.log,
+	}
+	return pb.NewRegistryClient(conn), nil
+}
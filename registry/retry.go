@@ -0,0 +1,200 @@
+package registry
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"math/rand"
+	"net"
+	"strings"
+	"sync"
+	"time"
+
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+)
+
+// Backoff delays between retries. The delay doubles with each attempt up
+// to retryMaxDelay and a random jitter of up to half the delay is
+// subtracted. A Retry-After delay longer than retryMaxAfter is not waited
+// for and the error is returned instead.
+const (
+	retryBaseDelay = 500 * time.Millisecond
+	retryMaxDelay  = 30 * time.Second
+	retryMaxAfter  = 2 * time.Minute
+)
+
+// idempotentMethods are the methods that are retried. Uploads are not
+// retried as they modify the upload session, nor are deletes as a retry
+// of a delete that succeeded fails. PutManifest is retried as putting the
+// same manifest again has the same result and response.
+var idempotentMethods = map[string]bool{
+	"/foxygoat.dreg.Registry/CheckV2":          true,
+	"/foxygoat.dreg.Registry/ListRepositories": true,
+	"/foxygoat.dreg.Registry/ListImageTags":    true,
+	"/foxygoat.dreg.Registry/GetDigest":        true,
+	"/foxygoat.dreg.Registry/GetManifest":      true,
+	"/foxygoat.dreg.Registry/HeadBlob":         true,
+	"/foxygoat.dreg.Registry/GetBlob":          true,
+	"/foxygoat.dreg.Registry/PutManifest":      true,
+}
+
+// retryConn is a grpc.ClientConnInterface that retries calls to
+// idempotent methods that fail with a transient error: a network error, a
+// timeout, or a 429, 502, 503 or 504 response from the registry. Retries
+// back off exponentially with jitter, or wait for the delay the registry
+// gives in a Retry-After header. Each attempt of a unary call is limited
+// to timeout if it is not zero. Retries are logged to log if it is not
+// nil.
+type retryConn struct {
+	next    grpc.ClientConnInterface
+	retries int
+	timeout time.Duration
+	log     io.Writer
+}
+
+func (c *retryConn) Invoke(ctx context.Context, method string, args, reply interface{}, opts ...grpc.CallOption) error {
+	for attempt := 0; ; attempt++ {
+		proto.Reset(reply.(proto.Message))
+		err := c.invoke(ctx, method, args, reply, opts...)
+		if err == nil || !c.retry(ctx, method, attempt, err) {
+			return err
+		}
+	}
+}
+
+func (c *retryConn) invoke(ctx context.Context, method string, args, reply interface{}, opts ...grpc.CallOption) error {
+	if c.timeout == 0 {
+		return c.next.Invoke(ctx, method, args, reply, opts...)
+	}
+	attemptCtx, cancel := context.WithTimeout(ctx, c.timeout)
+	defer cancel()
+	err := c.next.Invoke(attemptCtx, method, args, reply, opts...)
+	if err != nil && ctx.Err() == nil && errors.Is(attemptCtx.Err(), context.DeadlineExceeded) {
+		return status.Errorf(codes.DeadlineExceeded, "%s: no response after %v", methodName(method), c.timeout)
+	}
+	return err
+}
+
+// NewStream returns a stream that retries the request of a
+// server-streaming idempotent method. Errors receiving from the stream are
+// not retried.
+func (c *retryConn) NewStream(ctx context.Context, desc *grpc.StreamDesc, method string, opts ...grpc.CallOption) (grpc.ClientStream, error) {
+	s, err := c.next.NewStream(ctx, desc, method, opts...)
+	if err != nil || desc.ClientStreams || !idempotentMethods[method] {
+		return s, err
+	}
+	return &retryStream{ClientStream: s, conn: c, ctx: ctx, desc: desc, method: method, opts: opts}, nil
+}
+
+// retry waits before the next attempt of a call to method that failed with
+// err and reports whether to make it. It returns false without waiting if
+// the method is not idempotent, err is not transient, there are no
+// retries left or ctx is done.
+func (c *retryConn) retry(ctx context.Context, method string, attempt int, err error) bool {
+	if !idempotentMethods[method] || attempt >= c.retries || ctx.Err() != nil {
+		return false
+	}
+	delay, ok := retryDelay(attempt, err)
+	if !ok {
+		return false
+	}
+	if c.log != nil {
+		fmt.Fprintf(c.log, "Retrying %s in %v: %v\n", methodName(method), delay.Round(time.Millisecond), err)
+	}
+	t := time.NewTimer(delay)
+	defer t.Stop()
+	select {
+	case <-ctx.Done():
+		return false
+	case <-t.C:
+		return true
+	}
+}
+
+// retryDelay returns the delay before retrying after err on attempt, which
+// counts from zero. It returns false if err is not transient.
+func retryDelay(attempt int, err error) (time.Duration, bool) {
+	var opErr *net.OpError
+	if errors.As(err, &opErr) {
+		return backoffDelay(attempt), true
+	}
+	st, ok := status.FromError(err)
+	if !ok {
+		return 0, false
+	}
+	// 429 is ResourceExhausted, 502 and 503 are Unavailable and 504 is
+	// DeadlineExceeded. Other 5xx responses, such as 501, are permanent.
+	switch st.Code() {
+	case codes.Unavailable, codes.ResourceExhausted, codes.DeadlineExceeded:
+	default:
+		return 0, false
+	}
+	for _, detail := range st.Details() {
+		if info, ok := detail.(*errdetails.RetryInfo); ok && info.RetryDelay != nil {
+			delay := info.RetryDelay.AsDuration()
+			return delay, delay <= retryMaxAfter
+		}
+	}
+	return backoffDelay(attempt), true
+}
+
+// jitter is the random source of backoff jitter. The global math/rand
+// source is not seeded before Go 1.20, so clients would retry in step.
+var jitter = struct {
+	sync.Mutex
+	*rand.Rand
+}{Rand: rand.New(rand.NewSource(time.Now().UnixNano()))}
+
+func backoffDelay(attempt int) time.Duration {
+	delay := retryMaxDelay
+	if attempt < 16 && retryBaseDelay<<attempt < retryMaxDelay {
+		delay = retryBaseDelay << attempt
+	}
+	jitter.Lock()
+	defer jitter.Unlock()
+	return delay - time.Duration(jitter.Int63n(int64(delay/2)))
+}
+
+// methodName returns the name of a method without its service, such as
+// "GetManifest" for "/foxygoat.dreg.Registry/GetManifest".
+func methodName(method string) string {
+	return method[strings.LastIndex(method, "/")+1:]
+}
+
+// retryStream is a grpc.ClientStream for a server-streaming method that
+// retries the request when the send direction is closed, which is when
+// rawStream makes the request.
+type retryStream struct {
+	grpc.ClientStream
+	conn   *retryConn
+	ctx    context.Context
+	desc   *grpc.StreamDesc
+	method string
+	opts   []grpc.CallOption
+	req    interface{}
+}
+
+func (s *retryStream) SendMsg(m interface{}) error {
+	s.req = m
+	return s.ClientStream.SendMsg(m)
+}
+
+func (s *retryStream) CloseSend() error {
+	for attempt := 0; ; attempt++ {
+		err := s.ClientStream.CloseSend()
+		if err == nil || !s.conn.retry(s.ctx, s.method, attempt, err) {
+			return err
+		}
+		if s.ClientStream, err = s.conn.next.NewStream(s.ctx, s.desc, s.method, s.opts...); err != nil {
+			return err
+		}
+		if err := s.ClientStream.SendMsg(s.req); err != nil {
+			return err
+		}
+	}
+}
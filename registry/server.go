@@ -0,0 +1,492 @@
+package registry
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"hash"
+	"io"
+	"io/fs"
+	"net/http"
+	"net/url"
+	"regexp"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+
+	"foxygo.at/dreg/pb"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+var (
+	nameRE   = regexp.MustCompile(`^[a-z0-9]+(?:(?:[._]|__|[-]*)[a-z0-9]+)*(?:/[a-z0-9]+(?:(?:[._]|__|[-]*)[a-z0-9]+)*)*$`)
+	tagRE    = regexp.MustCompile(`^[\w][\w.-]{0,127}$`)
+	digestRE = regexp.MustCompile(`^sha256:[a-f0-9]{64}$`)
+)
+
+// Server is a registry server storing images in a Storage. Serve it over
+// HTTP with NewHandler:
+//
+//	srv := registry.NewServer(registry.NewMemStorage())
+//	http.ListenAndServe("localhost:5000", registry.NewHandler(srv))
+//
+// It supports pushing, pulling, listing and deleting images with sha256
+// digests, without authentication. Blob uploads are written to the
+// Storage as their chunks are received. Uploads not used for UploadTimeout
+// are deleted when another upload is started.
+type Server struct {
+	pb.UnimplementedRegistryServer
+	storage Storage
+	// uploadTimeout is how long an upload is kept without activity.
+	uploadTimeout time.Duration
+
+	mu      sync.Mutex
+	uploads map[string]*upload
+}
+
+// UploadTimeout is how long a Server keeps a blob upload after the last
+// request for it.
+const UploadTimeout = time.Hour
+
+// upload is a blob upload in progress.
+type upload struct {
+	name string
+	// used is when the upload was last requested. It is guarded by
+	// Server.mu.
+	used time.Time
+
+	// mu serialises writes to the upload, which is stored in the
+	// Storage.
+	mu   sync.Mutex
+	size int64
+	hash hash.Hash
+	// failed is set if writing to the Storage failed, leaving the
+	// upload in an unknown state.
+	failed bool
+}
+
+// NewServer returns a Server storing images in storage.
+func NewServer(storage Storage) *Server {
+	return &Server{storage: storage, uploadTimeout: UploadTimeout, uploads: map[string]*upload{}}
+}
+
+func (s *Server) CheckV2(ctx context.Context, req *pb.CheckV2Request) (*pb.CheckV2Response, error) {
+	return &pb.CheckV2Response{}, nil
+}
+
+func (s *Server) ListRepositories(ctx context.Context, req *pb.ListRepositoriesRequest) (*pb.ListRepositoriesResponse, error) {
+	repos, err := s.storage.Repositories()
+	if err != nil {
+		return nil, storageError(err)
+	}
+	page, link := paginate(repos, req.Last, req.N, "/v2/_catalog")
+	return &pb.ListRepositoriesResponse{Repositories: page, Link: link}, nil
+}
+
+func (s *Server) ListImageTags(ctx context.Context, req *pb.ListImageTagsRequest) (*pb.ListImageTagsResponse, error) {
+	if err := checkName(req.Name); err != nil {
+		return nil, err
+	}
+	tags, err := s.storage.Tags(req.Name)
+	if err != nil {
+		return nil, storageError(err)
+	}
+	page, link := paginate(tags, req.Last, req.N, "/v2/"+req.Name+"/tags/list")
+	return &pb.ListImageTagsResponse{Name: req.Name, Tags: page, Link: link}, nil
+}
+
+// paginate returns up to n of the sorted items after last, and a Link
+// header value for the next page at path if there are more. n of zero
+// returns all items.
+func paginate(items []string, last string, n int32, path string) ([]string, string) {
+	i := sort.SearchStrings(items, last)
+	if i < len(items) && items[i] == last {
+		i++
+	}
+	items = items[i:]
+	if n <= 0 || int(n) >= len(items) {
+		return items, ""
+	}
+	items = items[:n]
+	q := url.Values{"last": {items[n-1]}, "n": {strconv.Itoa(int(n))}}
+	return items, fmt.Sprintf(`<%s?%s>; rel="next"`, path, q.Encode())
+}
+
+func (s *Server) GetDigest(ctx context.Context, req *pb.GetDigestRequest) (*pb.GetDigestResponse, error) {
+	digest, _, _, err := s.manifest(req.Name, req.Reference)
+	if err != nil {
+		return nil, err
+	}
+	return &pb.GetDigestResponse{Digest: digest}, nil
+}
+
+func (s *Server) GetManifest(ctx context.Context, req *pb.GetManifestRequest) (*pb.GetManifestResponse, error) {
+	digest, mediaType, raw, err := s.manifest(req.Name, req.Reference)
+	if err != nil {
+		return nil, err
+	}
+	return &pb.GetManifestResponse{Digest: digest, MediaType: mediaType, Raw: raw}, nil
+}
+
+// manifest returns the digest, media type and contents of the manifest
+// that reference, a tag or digest, refers to in the named repository.
+func (s *Server) manifest(name, reference string) (string, string, []byte, error) {
+	if err := checkName(name); err != nil {
+		return "", "", nil, err
+	}
+	digest, err := s.resolve(name, reference)
+	if err != nil {
+		return "", "", nil, err
+	}
+	mediaType, raw, err := s.storage.GetManifest(name, digest)
+	if err != nil {
+		return "", "", nil, manifestError(err, name, reference)
+	}
+	return digest, mediaType, raw, nil
+}
+
+func (s *Server) resolve(name, reference string) (string, error) {
+	if digestRE.MatchString(reference) {
+		return reference, nil
+	}
+	if !tagRE.MatchString(reference) {
+		return "", newError(codes.InvalidArgument, "TAG_INVALID", "invalid tag or digest %q", reference)
+	}
+	digest, err := s.storage.GetTag(name, reference)
+	if err != nil {
+		return "", manifestError(err, name, reference)
+	}
+	return digest, nil
+}
+
+func (s *Server) HeadBlob(ctx context.Context, req *pb.HeadBlobRequest) (*pb.HeadBlobResponse, error) {
+	if err := checkBlob(req.Name, req.Digest); err != nil {
+		return nil, err
+	}
+	size, err := s.storage.StatBlob(req.Name, req.Digest)
+	if err != nil {
+		return nil, blobError(err, req.Name, req.Digest)
+	}
+	return &pb.HeadBlobResponse{Digest: req.Digest, Size: uint64(size)}, nil
+}
+
+func (s *Server) GetBlob(req *pb.GetBlobRequest, stream pb.Registry_GetBlobServer) error {
+	if err := checkBlob(req.Name, req.Digest); err != nil {
+		return err
+	}
+	f, err := s.storage.OpenBlob(req.Name, req.Digest)
+	if err != nil {
+		return blobError(err, req.Name, req.Digest)
+	}
+	defer f.Close()
+	size, err := f.Seek(0, io.SeekEnd)
+	if err != nil {
+		return err
+	}
+	if req.Offset > 0 && req.Offset >= uint64(size) {
+		return newError(codes.OutOfRange, "BLOB_UNKNOWN", "offset %d is beyond the end of %s", req.Offset, req.Digest)
+	}
+	if _, err := f.Seek(int64(req.Offset), io.SeekStart); err != nil {
+		return err
+	}
+	resp := &pb.GetBlobResponse{ContentLength: uint64(size) - req.Offset}
+	if req.Offset > 0 {
+		// A request with a Range header is answered with 206 Partial
+		// Content, which must have a Content-Range header.
+		resp.ContentRange = fmt.Sprintf("bytes %d-%d/%d", req.Offset, size-1, size)
+		if err := stream.SetHeader(metadata.Pairs(httpCodeHeader, strconv.Itoa(http.StatusPartialContent))); err != nil {
+			return err
+		}
+	}
+	buf := make([]byte, streamChunkSize)
+	for {
+		n, err := io.ReadFull(f, buf)
+		if n > 0 {
+			resp.Data = buf[:n]
+			if err := stream.Send(resp); err != nil {
+				return err
+			}
+			resp = &pb.GetBlobResponse{}
+		}
+		if errors.Is(err, io.EOF) || errors.Is(err, io.ErrUnexpectedEOF) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+	}
+}
+
+func (s *Server) DeleteBlob(ctx context.Context, req *pb.DeleteBlobRequest) (*pb.DeleteBlobResponse, error) {
+	if err := checkBlob(req.Name, req.Digest); err != nil {
+		return nil, err
+	}
+	if err := s.storage.DeleteBlob(req.Name, req.Digest); err != nil {
+		return nil, blobError(err, req.Name, req.Digest)
+	}
+	return &pb.DeleteBlobResponse{}, nil
+}
+
+func (s *Server) StartBlobUpload(ctx context.Context, req *pb.StartBlobUploadRequest) (*pb.StartBlobUploadResponse, error) {
+	if err := checkName(req.Name); err != nil {
+		return nil, err
+	}
+	if req.Mount != "" && req.From != "" && digestRE.MatchString(req.Mount) && nameRE.MatchString(req.From) {
+		if err := s.mount(req.Name, req.From, req.Mount); err == nil {
+			// A completed mount is 201 Created rather than 202 Accepted.
+			_ = grpc.SetHeader(ctx, metadata.Pairs(httpCodeHeader, strconv.Itoa(http.StatusCreated)))
+			return &pb.StartBlobUploadResponse{
+				Location: "/v2/" + req.Name + "/blobs/" + req.Mount,
+				Digest:   req.Mount,
+			}, nil
+		}
+		// Fall back to an upload if the blob cannot be mounted.
+	}
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return nil, err
+	}
+	uuid := hex.EncodeToString(b)
+	s.expireUploads()
+	if err := s.storage.StartUpload(uuid); err != nil {
+		return nil, storageError(err)
+	}
+	s.mu.Lock()
+	s.uploads[uuid] = &upload{name: req.Name, used: time.Now(), hash: sha256.New()}
+	s.mu.Unlock()
+	return &pb.StartBlobUploadResponse{
+		Location: "/v2/" + req.Name + "/blobs/uploads/" + uuid,
+		Uuid:     uuid,
+	}, nil
+}
+
+// mount stores the blob with the given digest in the repository from in
+// the named repository too.
+func (s *Server) mount(name, from, digest string) error {
+	f, err := s.storage.OpenBlob(from, digest)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	return s.storage.PutBlob(name, digest, f)
+}
+
+func (s *Server) UploadBlobChunk(ctx context.Context, req *pb.UploadBlobChunkRequest) (*pb.UploadBlobChunkResponse, error) {
+	u, err := s.upload(req.Name, req.Uuid, false)
+	if err != nil {
+		return nil, err
+	}
+	u.mu.Lock()
+	defer u.mu.Unlock()
+	if req.Range != "" {
+		start, _ := cut(req.Range, "-")
+		if start != strconv.FormatInt(u.size, 10) {
+			return nil, newError(codes.OutOfRange, "BLOB_UPLOAD_INVALID", "chunk range %s does not follow %d bytes received", req.Range, u.size)
+		}
+	}
+	if err := s.write(req.Uuid, u, bodyReader(ctx, req.Data)); err != nil {
+		return nil, err
+	}
+	return &pb.UploadBlobChunkResponse{
+		Location: "/v2/" + req.Name + "/blobs/uploads/" + req.Uuid,
+		Range:    fmt.Sprintf("0-%d", u.size-1),
+	}, nil
+}
+
+func (s *Server) CompleteBlobUpload(ctx context.Context, req *pb.CompleteBlobUploadRequest) (*pb.CompleteBlobUploadResponse, error) {
+	u, err := s.upload(req.Name, req.Uuid, true)
+	if err != nil {
+		return nil, err
+	}
+	u.mu.Lock()
+	defer u.mu.Unlock()
+	defer func() { _ = s.storage.DeleteUpload(req.Uuid) }()
+	if !digestRE.MatchString(req.Digest) {
+		return nil, newError(codes.InvalidArgument, "DIGEST_INVALID", "invalid or unsupported digest %q", req.Digest)
+	}
+	if err := s.write(req.Uuid, u, bodyReader(ctx, req.Data)); err != nil {
+		return nil, err
+	}
+	if digest := "sha256:" + hex.EncodeToString(u.hash.Sum(nil)); digest != req.Digest {
+		return nil, newError(codes.InvalidArgument, "DIGEST_INVALID", "%s: digest mismatch: upload is %s", req.Digest, digest)
+	}
+	if err := s.storage.CommitUpload(req.Uuid, req.Name, req.Digest); err != nil {
+		return nil, storageError(err)
+	}
+	return &pb.CompleteBlobUploadResponse{Digest: req.Digest}, nil
+}
+
+// write appends the contents of r to the upload u with the given uuid.
+// u.mu must be held.
+func (s *Server) write(uuid string, u *upload, r io.Reader) error {
+	if u.failed {
+		return newError(codes.NotFound, "BLOB_UPLOAD_UNKNOWN", "blob upload %s failed", uuid)
+	}
+	size, err := s.storage.AppendUpload(uuid, io.TeeReader(r, u.hash))
+	if err != nil {
+		u.failed = true
+		return storageError(err)
+	}
+	u.size = size
+	return nil
+}
+
+// upload returns the upload with the given uuid to the named repository,
+// removing it from the uploads in progress if remove is set.
+func (s *Server) upload(name, uuid string, remove bool) (*upload, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	u := s.uploads[uuid]
+	if u == nil || u.name != name {
+		return nil, newError(codes.NotFound, "BLOB_UPLOAD_UNKNOWN", "blob upload %s unknown", uuid)
+	}
+	u.used = time.Now()
+	if remove {
+		delete(s.uploads, uuid)
+	}
+	return u, nil
+}
+
+// expireUploads deletes the uploads not used for longer than the upload
+// timeout.
+func (s *Server) expireUploads() {
+	s.mu.Lock()
+	expired := map[string]*upload{}
+	for uuid, u := range s.uploads {
+		if time.Since(u.used) > s.uploadTimeout {
+			expired[uuid] = u
+			delete(s.uploads, uuid)
+		}
+	}
+	s.mu.Unlock()
+	for uuid, u := range expired {
+		// Wait for any request still writing to the upload.
+		u.mu.Lock()
+		_ = s.storage.DeleteUpload(uuid)
+		u.mu.Unlock()
+	}
+}
+
+func (s *Server) PutManifest(ctx context.Context, req *pb.PutManifestRequest) (*pb.PutManifestResponse, error) {
+	if err := checkName(req.Name); err != nil {
+		return nil, err
+	}
+	digest := SHA256Digest(req.Raw)
+	isDigest := digestRE.MatchString(req.Reference)
+	if isDigest && req.Reference != digest {
+		return nil, newError(codes.InvalidArgument, "DIGEST_INVALID", "manifest digest is %s, not %s", digest, req.Reference)
+	}
+	if !isDigest && !tagRE.MatchString(req.Reference) {
+		return nil, newError(codes.InvalidArgument, "TAG_INVALID", "invalid tag %q", req.Reference)
+	}
+	m, err := ParseManifest(req.MediaType, req.Raw)
+	if err != nil {
+		return nil, newError(codes.InvalidArgument, "MANIFEST_INVALID", "%v", err)
+	}
+	if err := s.checkReferences(req.Name, m); err != nil {
+		return nil, err
+	}
+	mediaType := req.MediaType
+	if !IsManifestMediaType(mediaType) {
+		mediaType = ManifestMediaType(m)
+	}
+	if err := s.storage.PutManifest(req.Name, digest, mediaType, req.Raw); err != nil {
+		return nil, storageError(err)
+	}
+	if !isDigest {
+		if err := s.storage.PutTag(req.Name, req.Reference, digest); err != nil {
+			return nil, storageError(err)
+		}
+	}
+	return &pb.PutManifestResponse{Digest: digest}, nil
+}
+
+// checkReferences returns an error if the config and layers of an image
+// manifest or the manifests of an image index are not in the named
+// repository.
+func (s *Server) checkReferences(name string, m *pb.Manifest) error {
+	if image := m.GetImage(); image != nil {
+		for _, desc := range append([]*pb.Descriptor{image.Config}, image.Layers...) {
+			if desc == nil || len(desc.Urls) > 0 {
+				// Foreign layers are not pushed.
+				continue
+			}
+			if _, err := s.storage.StatBlob(name, desc.Digest); err != nil {
+				return newError(codes.InvalidArgument, "MANIFEST_BLOB_UNKNOWN", "blob %s unknown to registry", desc.Digest)
+			}
+		}
+		return nil
+	}
+	for _, desc := range m.GetIndex().GetManifests() {
+		if _, _, err := s.storage.GetManifest(name, desc.Digest); err != nil {
+			return newError(codes.InvalidArgument, "MANIFEST_BLOB_UNKNOWN", "manifest %s unknown to registry", desc.Digest)
+		}
+	}
+	return nil
+}
+
+// DeleteImage deletes a manifest and its tags if reference is a digest,
+// or just the tag if it is a tag.
+func (s *Server) DeleteImage(ctx context.Context, req *pb.DeleteImageRequest) (*pb.DeleteImageResponse, error) {
+	if err := checkName(req.Name); err != nil {
+		return nil, err
+	}
+	var err error
+	switch {
+	case digestRE.MatchString(req.Reference):
+		err = s.storage.DeleteManifest(req.Name, req.Reference)
+	case tagRE.MatchString(req.Reference):
+		err = s.storage.DeleteTag(req.Name, req.Reference)
+	default:
+		return nil, newError(codes.InvalidArgument, "TAG_INVALID", "invalid tag or digest %q", req.Reference)
+	}
+	if err != nil {
+		return nil, manifestError(err, req.Name, req.Reference)
+	}
+	return &pb.DeleteImageResponse{}, nil
+}
+
+func checkName(name string) error {
+	if !nameRE.MatchString(name) || len(name) > 255 {
+		return newError(codes.InvalidArgument, "NAME_INVALID", "invalid repository name %q", name)
+	}
+	return nil
+}
+
+func checkBlob(name, digest string) error {
+	if err := checkName(name); err != nil {
+		return err
+	}
+	if !digestRE.MatchString(digest) {
+		return newError(codes.InvalidArgument, "DIGEST_INVALID", "invalid or unsupported digest %q", digest)
+	}
+	return nil
+}
+
+func manifestError(err error, name, reference string) error {
+	if errors.Is(err, fs.ErrNotExist) {
+		return newError(codes.NotFound, "MANIFEST_UNKNOWN", "manifest %s:%s unknown", name, reference)
+	}
+	return storageError(err)
+}
+
+func blobError(err error, name, digest string) error {
+	if errors.Is(err, fs.ErrNotExist) {
+		return newError(codes.NotFound, "BLOB_UNKNOWN", "blob %s@%s unknown", name, digest)
+	}
+	return storageError(err)
+}
+
+func storageError(err error) error {
+	if errors.Is(err, fs.ErrNotExist) {
+		return newError(codes.NotFound, "NAME_UNKNOWN", "%v", err)
+	}
+	return status.Error(codes.Internal, err.Error())
+}
@@ -0,0 +1,418 @@
+package registry
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+// testServer is a Server served over HTTP for testing the /v2/ routes.
+type testServer struct {
+	t   *testing.T
+	srv *Server
+	url string
+}
+
+func newTestServer(t *testing.T, storage Storage) *testServer {
+	t.Helper()
+	srv := NewServer(storage)
+	hs := httptest.NewServer(NewHandler(srv))
+	t.Cleanup(hs.Close)
+	return &testServer{t: t, srv: srv, url: hs.URL}
+}
+
+// storages returns the Storage implementations to test the server with.
+func storages(t *testing.T) map[string]Storage {
+	t.Helper()
+	fs, err := NewFSStorage(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	return map[string]Storage{"mem": NewMemStorage(), "fs": fs}
+}
+
+// do makes a request with the given headers, as "Key: value" strings, and
+// returns the response with its body read.
+func (ts *testServer) do(method, path string, body []byte, headers ...string) (*http.Response, []byte) {
+	ts.t.Helper()
+	req, err := http.NewRequest(method, ts.url+path, bytes.NewReader(body))
+	if err != nil {
+		ts.t.Fatal(err)
+	}
+	for _, h := range headers {
+		key, val := cut(h, ": ")
+		req.Header.Set(key, val)
+	}
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		ts.t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	b, err := io.ReadAll(resp.Body)
+	if err != nil {
+		ts.t.Fatal(err)
+	}
+	return resp, b
+}
+
+// expect makes a request and fails the test if the response status is not
+// want.
+func (ts *testServer) expect(want int, method, path string, body []byte, headers ...string) (*http.Response, []byte) {
+	ts.t.Helper()
+	resp, b := ts.do(method, path, body, headers...)
+	if resp.StatusCode != want {
+		ts.t.Fatalf("%s %s: got status %d, want %d: %s", method, path, resp.StatusCode, want, b)
+	}
+	return resp, b
+}
+
+// pushBlob uploads data to the named repository monolithically and
+// returns its digest.
+func (ts *testServer) pushBlob(name string, data []byte) string {
+	ts.t.Helper()
+	digest := SHA256Digest(data)
+	resp, _ := ts.expect(http.StatusAccepted, "POST", "/v2/"+name+"/blobs/uploads/", nil)
+	ts.expect(http.StatusCreated, "PUT", resp.Header.Get("Location")+"?digest="+digest, data, "Content-Type: application/octet-stream")
+	return digest
+}
+
+// pushImage pushes an image manifest with a config and one layer to
+// name:tag and returns the manifest and its digest.
+func (ts *testServer) pushImage(name, tag, layer string) ([]byte, string) {
+	ts.t.Helper()
+	config := []byte(`{"architecture":"amd64","os":"linux"}`)
+	configDigest := ts.pushBlob(name, config)
+	layerDigest := ts.pushBlob(name, []byte(layer))
+	manifest := []byte(fmt.Sprintf(`{"schemaVersion":2,"mediaType":%q,"config":{"mediaType":"application/vnd.oci.image.config.v1+json","digest":%q,"size":%d},"layers":[{"mediaType":"application/vnd.oci.image.layer.v1.tar","digest":%q,"size":%d}]}`,
+		MediaTypeOCIManifest, configDigest, len(config), layerDigest, len(layer)))
+	resp, _ := ts.expect(http.StatusCreated, "PUT", "/v2/"+name+"/manifests/"+tag, manifest, "Content-Type: "+MediaTypeOCIManifest)
+	digest := SHA256Digest(manifest)
+	if got := resp.Header.Get("Docker-Content-Digest"); got != digest {
+		ts.t.Fatalf("PUT manifest: got digest %q, want %q", got, digest)
+	}
+	return manifest, digest
+}
+
+func TestServerCheckV2(t *testing.T) {
+	ts := newTestServer(t, NewMemStorage())
+	resp, _ := ts.expect(http.StatusOK, "GET", "/v2/", nil)
+	if got := resp.Header.Get("Docker-Distribution-API-Version"); got != "registry/2.0" {
+		t.Errorf("got API version %q", got)
+	}
+	ts.expect(http.StatusNotFound, "GET", "/v3/", nil)
+}
+
+func TestServerPagination(t *testing.T) {
+	ts := newTestServer(t, NewMemStorage())
+	for _, name := range []string{"c", "a", "b/x"} {
+		ts.pushImage(name, "latest", "layer")
+	}
+	for _, tag := range []string{"v3", "v1", "v2"} {
+		ts.pushImage("a", tag, "layer")
+	}
+	tests := []struct {
+		path     string
+		field    string
+		want     []string
+		wantNext string
+	}{
+		{"/v2/_catalog", "repositories", []string{"a", "b/x", "c"}, ""},
+		{"/v2/_catalog?n=2", "repositories", []string{"a", "b/x"}, `</v2/_catalog?last=b%2Fx&n=2>; rel="next"`},
+		{"/v2/_catalog?n=2&last=b%2Fx", "repositories", []string{"c"}, ""},
+		{"/v2/_catalog?last=c", "repositories", []string{}, ""},
+		{"/v2/a/tags/list", "tags", []string{"latest", "v1", "v2", "v3"}, ""},
+		{"/v2/a/tags/list?n=3", "tags", []string{"latest", "v1", "v2"}, `</v2/a/tags/list?last=v2&n=3>; rel="next"`},
+		{"/v2/a/tags/list?n=3&last=v2", "tags", []string{"v3"}, ""},
+	}
+	for _, tt := range tests {
+		resp, b := ts.expect(http.StatusOK, "GET", tt.path, nil)
+		var body map[string]json.RawMessage
+		var got []string
+		if err := json.Unmarshal(b, &body); err != nil {
+			t.Fatalf("%s: %v: %s", tt.path, err, b)
+		}
+		if err := json.Unmarshal(body[tt.field], &got); body[tt.field] != nil && err != nil {
+			t.Fatalf("%s: %v: %s", tt.path, err, b)
+		}
+		if strings.Join(got, ",") != strings.Join(tt.want, ",") {
+			t.Errorf("%s: got %s %q, want %q", tt.path, tt.field, got, tt.want)
+		}
+		if got := resp.Header.Get("Link"); got != tt.wantNext {
+			t.Errorf("%s: got Link %q, want %q", tt.path, got, tt.wantNext)
+		}
+	}
+	ts.expect(http.StatusNotFound, "GET", "/v2/missing/tags/list", nil)
+}
+
+func TestServerManifests(t *testing.T) {
+	for name, storage := range storages(t) {
+		t.Run(name, func(t *testing.T) {
+			ts := newTestServer(t, storage)
+			manifest, digest := ts.pushImage("a/b", "v1", "layer")
+
+			for _, ref := range []string{"v1", digest} {
+				resp, b := ts.expect(http.StatusOK, "GET", "/v2/a/b/manifests/"+ref, nil, "Accept: "+MediaTypeOCIManifest)
+				if !bytes.Equal(b, manifest) {
+					t.Errorf("GET %s: got manifest %s", ref, b)
+				}
+				if got := resp.Header.Get("Content-Type"); got != MediaTypeOCIManifest {
+					t.Errorf("GET %s: got Content-Type %q", ref, got)
+				}
+				if got := resp.Header.Get("Docker-Content-Digest"); got != digest {
+					t.Errorf("GET %s: got digest %q", ref, got)
+				}
+				resp, b = ts.expect(http.StatusOK, "HEAD", "/v2/a/b/manifests/"+ref, nil)
+				if len(b) != 0 || resp.Header.Get("Docker-Content-Digest") != digest {
+					t.Errorf("HEAD %s: got digest %q and body %q", ref, resp.Header.Get("Docker-Content-Digest"), b)
+				}
+			}
+			ts.expect(http.StatusNotFound, "GET", "/v2/a/b/manifests/v2", nil)
+			ts.expect(http.StatusNotFound, "HEAD", "/v2/a/b/manifests/v2", nil)
+			ts.expect(http.StatusBadRequest, "GET", "/v2/a/b/manifests/-bad", nil)
+
+			// Manifests must refer to blobs in the repository and match
+			// the digest they are put by.
+			ts.expect(http.StatusBadRequest, "PUT", "/v2/other/manifests/v1", manifest, "Content-Type: "+MediaTypeOCIManifest)
+			ts.expect(http.StatusBadRequest, "PUT", "/v2/a/b/manifests/"+SHA256Digest(nil), manifest, "Content-Type: "+MediaTypeOCIManifest)
+			ts.expect(http.StatusCreated, "PUT", "/v2/a/b/manifests/"+digest, manifest, "Content-Type: "+MediaTypeOCIManifest)
+			ts.expect(http.StatusCreated, "PUT", "/v2/a/b/manifests/v2", manifest, "Content-Type: "+MediaTypeOCIManifest)
+
+			// Deleting a tag keeps the manifest and its other tags.
+			ts.expect(http.StatusAccepted, "DELETE", "/v2/a/b/manifests/v1", nil)
+			ts.expect(http.StatusNotFound, "GET", "/v2/a/b/manifests/v1", nil)
+			ts.expect(http.StatusOK, "GET", "/v2/a/b/manifests/v2", nil)
+			ts.expect(http.StatusNotFound, "DELETE", "/v2/a/b/manifests/v1", nil)
+
+			// Deleting a digest deletes its tags.
+			ts.expect(http.StatusAccepted, "DELETE", "/v2/a/b/manifests/"+digest, nil)
+			ts.expect(http.StatusNotFound, "GET", "/v2/a/b/manifests/"+digest, nil)
+			ts.expect(http.StatusNotFound, "GET", "/v2/a/b/manifests/v2", nil)
+		})
+	}
+}
+
+func TestServerMonolithicUpload(t *testing.T) {
+	for name, storage := range storages(t) {
+		t.Run(name, func(t *testing.T) {
+			ts := newTestServer(t, storage)
+			data := []byte("monolithic upload")
+			digest := SHA256Digest(data)
+			ts.expect(http.StatusNotFound, "HEAD", "/v2/a/blobs/"+digest, nil)
+
+			resp, _ := ts.expect(http.StatusAccepted, "POST", "/v2/a/blobs/uploads/", nil)
+			location := resp.Header.Get("Location")
+			if !strings.HasPrefix(location, "/v2/a/blobs/uploads/") || resp.Header.Get("Docker-Upload-UUID") == "" {
+				t.Fatalf("got Location %q and UUID %q", location, resp.Header.Get("Docker-Upload-UUID"))
+			}
+			resp, _ = ts.expect(http.StatusCreated, "PUT", location+"?digest="+digest, data, "Content-Type: application/octet-stream")
+			if got := resp.Header.Get("Docker-Content-Digest"); got != digest {
+				t.Errorf("got digest %q, want %q", got, digest)
+			}
+			// The upload is gone once completed.
+			ts.expect(http.StatusNotFound, "PUT", location+"?digest="+digest, data, "Content-Type: application/octet-stream")
+
+			resp, _ = ts.expect(http.StatusOK, "HEAD", "/v2/a/blobs/"+digest, nil)
+			if resp.ContentLength != int64(len(data)) {
+				t.Errorf("HEAD: got length %d, want %d", resp.ContentLength, len(data))
+			}
+			_, b := ts.expect(http.StatusOK, "GET", "/v2/a/blobs/"+digest, nil)
+			if !bytes.Equal(b, data) {
+				t.Errorf("GET: got %q, want %q", b, data)
+			}
+			ts.expect(http.StatusNotFound, "GET", "/v2/other/blobs/"+digest, nil)
+
+			// An upload that does not match its digest is rejected.
+			resp, _ = ts.expect(http.StatusAccepted, "POST", "/v2/a/blobs/uploads/", nil)
+			other := SHA256Digest([]byte("other"))
+			ts.expect(http.StatusBadRequest, "PUT", resp.Header.Get("Location")+"?digest="+other, data, "Content-Type: application/octet-stream")
+			ts.expect(http.StatusNotFound, "HEAD", "/v2/a/blobs/"+other, nil)
+
+			ts.expect(http.StatusAccepted, "DELETE", "/v2/a/blobs/"+digest, nil)
+			ts.expect(http.StatusNotFound, "HEAD", "/v2/a/blobs/"+digest, nil)
+		})
+	}
+}
+
+func TestServerChunkedUpload(t *testing.T) {
+	for name, storage := range storages(t) {
+		t.Run(name, func(t *testing.T) {
+			ts := newTestServer(t, storage)
+			chunks := []string{"chunk one,", "chunk two,", "last"}
+			data := []byte(strings.Join(chunks, ""))
+			digest := SHA256Digest(data)
+
+			resp, _ := ts.expect(http.StatusAccepted, "POST", "/v2/a/blobs/uploads/", nil)
+			location := resp.Header.Get("Location")
+			offset := 0
+			for _, chunk := range chunks[:2] {
+				rng := fmt.Sprintf("Content-Range: %d-%d", offset, offset+len(chunk)-1)
+				resp, _ = ts.expect(http.StatusAccepted, "PATCH", location, []byte(chunk), rng, "Content-Type: application/octet-stream")
+				offset += len(chunk)
+				if got, want := resp.Header.Get("Range"), fmt.Sprintf("0-%d", offset-1); got != want {
+					t.Errorf("PATCH: got Range %q, want %q", got, want)
+				}
+				location = resp.Header.Get("Location")
+			}
+			// A chunk that does not follow the data received is rejected.
+			ts.expect(http.StatusRequestedRangeNotSatisfiable, "PATCH", location, []byte("x"), "Content-Range: 0-0", "Content-Type: application/octet-stream")
+
+			ts.expect(http.StatusCreated, "PUT", location+"?digest="+digest, []byte(chunks[2]), "Content-Type: application/octet-stream")
+			_, b := ts.expect(http.StatusOK, "GET", "/v2/a/blobs/"+digest, nil)
+			if !bytes.Equal(b, data) {
+				t.Errorf("GET: got %q, want %q", b, data)
+			}
+			ts.expect(http.StatusNotFound, "PATCH", location, []byte("x"), "Content-Type: application/octet-stream")
+			ts.expect(http.StatusNotFound, "PATCH", "/v2/a/blobs/uploads/unknown", []byte("x"), "Content-Type: application/octet-stream")
+		})
+	}
+}
+
+func TestServerUploadExpiry(t *testing.T) {
+	ts := newTestServer(t, NewMemStorage())
+	resp, _ := ts.expect(http.StatusAccepted, "POST", "/v2/a/blobs/uploads/", nil)
+	stale := resp.Header.Get("Location")
+	ts.expect(http.StatusAccepted, "PATCH", stale, []byte("data"), "Content-Type: application/octet-stream")
+
+	ts.srv.mu.Lock()
+	for _, u := range ts.srv.uploads {
+		u.used = time.Now().Add(-2 * UploadTimeout)
+	}
+	ts.srv.mu.Unlock()
+
+	resp, _ = ts.expect(http.StatusAccepted, "POST", "/v2/a/blobs/uploads/", nil)
+	fresh := resp.Header.Get("Location")
+	ts.expect(http.StatusNotFound, "PATCH", stale, []byte("more"), "Content-Type: application/octet-stream")
+	ts.expect(http.StatusAccepted, "PATCH", fresh, []byte("data"), "Content-Type: application/octet-stream")
+	if n := len(ts.srv.storage.(*memStorage).uploads); n != 1 {
+		t.Errorf("got %d uploads in storage, want 1", n)
+	}
+}
+
+// appendSignalStorage closes appending when AppendUpload first reads.
+type appendSignalStorage struct {
+	Storage
+	appending chan struct{}
+}
+
+func (s *appendSignalStorage) AppendUpload(id string, r io.Reader) (int64, error) {
+	return s.Storage.AppendUpload(id, &signalReader{r: r, s: s})
+}
+
+type signalReader struct {
+	r io.Reader
+	s *appendSignalStorage
+}
+
+func (r *signalReader) Read(p []byte) (int, error) {
+	n, err := r.r.Read(p)
+	if n > 0 && r.s.appending != nil {
+		close(r.s.appending)
+		r.s.appending = nil
+	}
+	return n, err
+}
+
+func TestServerStreamedUpload(t *testing.T) {
+	appending := make(chan struct{})
+	ts := newTestServer(t, &appendSignalStorage{Storage: NewMemStorage(), appending: appending})
+	data := []byte("first part,second part")
+	digest := SHA256Digest(data)
+	resp, _ := ts.expect(http.StatusAccepted, "POST", "/v2/a/blobs/uploads/", nil)
+
+	// The second part is only sent once storage has read the first, so
+	// the upload would hang if the body were read before storing it.
+	pr, pw := io.Pipe()
+	go func() {
+		pw.Write(data[:11])
+		select {
+		case <-appending:
+			pw.Write(data[11:])
+			pw.Close()
+		case <-time.After(5 * time.Second):
+			pw.CloseWithError(fmt.Errorf("body not streamed to storage"))
+		}
+	}()
+	req, err := http.NewRequest("PUT", ts.url+resp.Header.Get("Location")+"?digest="+digest, pr)
+	if err != nil {
+		t.Fatal(err)
+	}
+	req.Header.Set("Content-Type", "application/octet-stream")
+	resp, err = http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusCreated {
+		t.Fatalf("PUT: got status %d, want %d", resp.StatusCode, http.StatusCreated)
+	}
+	_, b := ts.expect(http.StatusOK, "GET", "/v2/a/blobs/"+digest, nil)
+	if !bytes.Equal(b, data) {
+		t.Errorf("GET: got %q, want %q", b, data)
+	}
+}
+
+func TestServerMount(t *testing.T) {
+	for name, storage := range storages(t) {
+		t.Run(name, func(t *testing.T) {
+			ts := newTestServer(t, storage)
+			data := []byte("mounted")
+			digest := ts.pushBlob("a", data)
+
+			resp, _ := ts.expect(http.StatusCreated, "POST", "/v2/b/blobs/uploads/?mount="+digest+"&from=a", nil)
+			if got := resp.Header.Get("Docker-Content-Digest"); got != digest {
+				t.Errorf("got digest %q, want %q", got, digest)
+			}
+			if got, want := resp.Header.Get("Location"), "/v2/b/blobs/"+digest; got != want {
+				t.Errorf("got Location %q, want %q", got, want)
+			}
+			_, b := ts.expect(http.StatusOK, "GET", "/v2/b/blobs/"+digest, nil)
+			if !bytes.Equal(b, data) {
+				t.Errorf("GET: got %q, want %q", b, data)
+			}
+
+			// A blob that cannot be mounted starts an upload instead.
+			missing := SHA256Digest([]byte("missing"))
+			resp, _ = ts.expect(http.StatusAccepted, "POST", "/v2/c/blobs/uploads/?mount="+missing+"&from=a", nil)
+			if resp.Header.Get("Docker-Upload-UUID") == "" {
+				t.Error("no upload started for missing blob")
+			}
+		})
+	}
+}
+
+func TestServerRangeRead(t *testing.T) {
+	for name, storage := range storages(t) {
+		t.Run(name, func(t *testing.T) {
+			ts := newTestServer(t, storage)
+			data := []byte("0123456789")
+			digest := ts.pushBlob("a", data)
+
+			resp, b := ts.expect(http.StatusPartialContent, "GET", "/v2/a/blobs/"+digest, nil, "Range: bytes=4-")
+			if string(b) != "456789" {
+				t.Errorf("got %q, want %q", b, "456789")
+			}
+			if got, want := resp.Header.Get("Content-Range"), "bytes 4-9/10"; got != want {
+				t.Errorf("got Content-Range %q, want %q", got, want)
+			}
+			if got, want := resp.Header.Get("Content-Length"), "6"; got != want {
+				t.Errorf("got Content-Length %q, want %q", got, want)
+			}
+			// A range from the start is the whole blob.
+			resp, b = ts.expect(http.StatusOK, "GET", "/v2/a/blobs/"+digest, nil, "Range: bytes=0-")
+			if !bytes.Equal(b, data) {
+				t.Errorf("got %q, want %q", b, data)
+			}
+			if got, want := resp.Header.Get("Content-Length"), "10"; got != want {
+				t.Errorf("got Content-Length %q, want %q", got, want)
+			}
+			ts.expect(http.StatusRequestedRangeNotSatisfiable, "GET", "/v2/a/blobs/"+digest, nil, "Range: bytes=10-")
+			ts.expect(http.StatusRequestedRangeNotSatisfiable, "GET", "/v2/a/blobs/"+digest, nil, "Range: bytes=11-")
+		})
+	}
+}
@@ -0,0 +1,610 @@
+package registry
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// Storage stores the manifests, tags and blobs of the repositories served
+// by a Server. Blobs and manifests are content addressed: a blob stored
+// in several repositories is stored once. Methods return an error wrapping
+// fs.ErrNotExist for repositories, tags, manifests and blobs that do not
+// exist.
+//
+// Server validates names, tags and digests before passing them to
+// Storage, and only sha256 digests are used.
+type Storage interface {
+	// Repositories returns the names of the repositories with at least
+	// one manifest, sorted.
+	Repositories() ([]string, error)
+	// Tags returns the tags of the named repository, sorted.
+	Tags(name string) ([]string, error)
+	// GetTag returns the digest of the manifest that tag refers to.
+	GetTag(name, tag string) (string, error)
+	// PutTag sets tag to refer to the manifest with the given digest.
+	PutTag(name, tag, digest string) error
+	DeleteTag(name, tag string) error
+
+	GetManifest(name, digest string) (mediaType string, raw []byte, err error)
+	PutManifest(name, digest, mediaType string, raw []byte) error
+	// DeleteManifest deletes a manifest and the tags that refer to it.
+	DeleteManifest(name, digest string) error
+
+	// StatBlob returns the size of a blob.
+	StatBlob(name, digest string) (int64, error)
+	OpenBlob(name, digest string) (io.ReadSeekCloser, error)
+	// PutBlob stores the blob read from r, which the caller has verified
+	// against digest. r may not be read if the blob is already stored
+	// for another repository.
+	PutBlob(name, digest string, r io.Reader) error
+	DeleteBlob(name, digest string) error
+
+	// StartUpload creates an empty blob upload with the given id.
+	StartUpload(id string) error
+	// AppendUpload appends the contents of r to an upload and returns
+	// its size.
+	AppendUpload(id string, r io.Reader) (int64, error)
+	// CommitUpload stores the contents of an upload, which the caller
+	// has verified against digest, as a blob of the named repository.
+	// The upload is deleted.
+	CommitUpload(id, name, digest string) error
+	// DeleteUpload deletes an upload.
+	DeleteUpload(id string) error
+}
+
+// memStorage is a Storage that keeps everything in memory.
+type memStorage struct {
+	mu    sync.Mutex
+	repos map[string]*memRepo
+	// blobs are the contents of the blobs and manifests of all
+	// repositories, by digest.
+	blobs map[string][]byte
+	// uploads are the contents of blob uploads by id.
+	uploads map[string][]byte
+}
+
+type memRepo struct {
+	tags map[string]string
+	// manifests are the media types of the repository's manifests,
+	// by digest.
+	manifests map[string]string
+	blobs     map[string]bool
+}
+
+// NewMemStorage returns a Storage that keeps images in memory.
+func NewMemStorage() Storage {
+	return &memStorage{repos: map[string]*memRepo{}, blobs: map[string][]byte{}, uploads: map[string][]byte{}}
+}
+
+func (s *memStorage) repo(name string, create bool) (*memRepo, error) {
+	r := s.repos[name]
+	if r == nil && create {
+		r = &memRepo{tags: map[string]string{}, manifests: map[string]string{}, blobs: map[string]bool{}}
+		s.repos[name] = r
+	}
+	if r == nil {
+		return nil, notExist("repository", name)
+	}
+	return r, nil
+}
+
+func (s *memStorage) Repositories() ([]string, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	var names []string
+	for name, r := range s.repos {
+		if len(r.manifests) > 0 {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	return names, nil
+}
+
+func (s *memStorage) Tags(name string) ([]string, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	r, err := s.repo(name, false)
+	if err != nil {
+		return nil, err
+	}
+	tags := make([]string, 0, len(r.tags))
+	for tag := range r.tags {
+		tags = append(tags, tag)
+	}
+	sort.Strings(tags)
+	return tags, nil
+}
+
+func (s *memStorage) GetTag(name, tag string) (string, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	r, err := s.repo(name, false)
+	if err != nil {
+		return "", err
+	}
+	digest, ok := r.tags[tag]
+	if !ok {
+		return "", notExist("tag", name+":"+tag)
+	}
+	return digest, nil
+}
+
+func (s *memStorage) PutTag(name, tag, digest string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	r, err := s.repo(name, false)
+	if err != nil {
+		return err
+	}
+	if _, ok := r.manifests[digest]; !ok {
+		return notExist("manifest", name+"@"+digest)
+	}
+	r.tags[tag] = digest
+	return nil
+}
+
+func (s *memStorage) DeleteTag(name, tag string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	r, err := s.repo(name, false)
+	if err != nil {
+		return err
+	}
+	if _, ok := r.tags[tag]; !ok {
+		return notExist("tag", name+":"+tag)
+	}
+	delete(r.tags, tag)
+	return nil
+}
+
+func (s *memStorage) GetManifest(name, digest string) (string, []byte, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	r, err := s.repo(name, false)
+	if err != nil {
+		return "", nil, err
+	}
+	mediaType, ok := r.manifests[digest]
+	if !ok {
+		return "", nil, notExist("manifest", name+"@"+digest)
+	}
+	return mediaType, s.blobs[digest], nil
+}
+
+func (s *memStorage) PutManifest(name, digest, mediaType string, raw []byte) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	r, _ := s.repo(name, true)
+	r.manifests[digest] = mediaType
+	s.blobs[digest] = append([]byte(nil), raw...)
+	return nil
+}
+
+func (s *memStorage) DeleteManifest(name, digest string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	r, err := s.repo(name, false)
+	if err != nil {
+		return err
+	}
+	if _, ok := r.manifests[digest]; !ok {
+		return notExist("manifest", name+"@"+digest)
+	}
+	delete(r.manifests, digest)
+	for tag, d := range r.tags {
+		if d == digest {
+			delete(r.tags, tag)
+		}
+	}
+	return nil
+}
+
+func (s *memStorage) blob(name, digest string) ([]byte, error) {
+	r, err := s.repo(name, false)
+	if err != nil {
+		return nil, err
+	}
+	if !r.blobs[digest] {
+		return nil, notExist("blob", name+"@"+digest)
+	}
+	return s.blobs[digest], nil
+}
+
+func (s *memStorage) StatBlob(name, digest string) (int64, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	b, err := s.blob(name, digest)
+	return int64(len(b)), err
+}
+
+func (s *memStorage) OpenBlob(name, digest string) (io.ReadSeekCloser, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	b, err := s.blob(name, digest)
+	if err != nil {
+		return nil, err
+	}
+	return nopSeekCloser{bytes.NewReader(b)}, nil
+}
+
+func (s *memStorage) PutBlob(name, digest string, r io.Reader) error {
+	s.mu.Lock()
+	_, ok := s.blobs[digest]
+	s.mu.Unlock()
+	var b []byte
+	if !ok {
+		var err error
+		if b, err = io.ReadAll(r); err != nil {
+			return err
+		}
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if b != nil {
+		s.blobs[digest] = b
+	}
+	repo, _ := s.repo(name, true)
+	repo.blobs[digest] = true
+	return nil
+}
+
+func (s *memStorage) DeleteBlob(name, digest string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, err := s.blob(name, digest); err != nil {
+		return err
+	}
+	delete(s.repos[name].blobs, digest)
+	return nil
+}
+
+func (s *memStorage) StartUpload(id string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.uploads[id] = nil
+	return nil
+}
+
+func (s *memStorage) AppendUpload(id string, r io.Reader) (int64, error) {
+	b, err := io.ReadAll(r)
+	if err != nil {
+		return 0, err
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	data, ok := s.uploads[id]
+	if !ok {
+		return 0, notExist("upload", id)
+	}
+	data = append(data, b...)
+	s.uploads[id] = data
+	return int64(len(data)), nil
+}
+
+func (s *memStorage) CommitUpload(id, name, digest string) error {
+	s.mu.Lock()
+	data, ok := s.uploads[id]
+	delete(s.uploads, id)
+	s.mu.Unlock()
+	if !ok {
+		return notExist("upload", id)
+	}
+	return s.PutBlob(name, digest, bytes.NewReader(data))
+}
+
+func (s *memStorage) DeleteUpload(id string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, ok := s.uploads[id]; !ok {
+		return notExist("upload", id)
+	}
+	delete(s.uploads, id)
+	return nil
+}
+
+type nopSeekCloser struct {
+	io.ReadSeeker
+}
+
+func (nopSeekCloser) Close() error { return nil }
+
+// fsStorage is a Storage that keeps images in a directory:
+//
+//	blobs/sha256/<hex>                              blob and manifest contents
+//	repositories/<name>/_blobs/sha256/<hex>         empty link to a blob
+//	repositories/<name>/_manifests/sha256/<hex>     manifest media type
+//	repositories/<name>/_tags/<tag>                 manifest digest
+//	uploads/<id>                                    blob upload in progress
+//
+// Repository path components cannot start with "_", so these directories
+// do not clash with repository names. Files are written atomically.
+// Deleted blobs and manifests are unlinked from the repository but their
+// contents are kept. Uploads do not outlive the Server, so uploads left
+// by a previous one are deleted by NewFSStorage.
+type fsStorage struct {
+	dir string
+}
+
+// NewFSStorage returns a Storage that keeps images in dir, which is
+// created if it does not exist.
+func NewFSStorage(dir string) (Storage, error) {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, err
+	}
+	s := &fsStorage{dir: dir}
+	if err := os.RemoveAll(s.uploadPath("")); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+func (s *fsStorage) repoPath(name string, elem ...string) string {
+	return filepath.Join(append([]string{s.dir, "repositories", filepath.FromSlash(name)}, elem...)...)
+}
+
+func (s *fsStorage) blobPath(digest string) string {
+	alg, hex := cut(digest, ":")
+	return filepath.Join(s.dir, "blobs", alg, hex)
+}
+
+func (s *fsStorage) linkPath(name, kind, digest string) string {
+	alg, hex := cut(digest, ":")
+	return s.repoPath(name, kind, alg, hex)
+}
+
+func (s *fsStorage) uploadPath(id string) string {
+	return filepath.Join(s.dir, "uploads", id)
+}
+
+func (s *fsStorage) Repositories() ([]string, error) {
+	root := filepath.Join(s.dir, "repositories")
+	var names []string
+	err := filepath.WalkDir(root, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if !d.IsDir() || !strings.HasPrefix(d.Name(), "_") {
+			return nil
+		}
+		if d.Name() == "_manifests" && hasFiles(path) {
+			name, err := filepath.Rel(root, filepath.Dir(path))
+			if err != nil {
+				return err
+			}
+			names = append(names, filepath.ToSlash(name))
+		}
+		return filepath.SkipDir
+	})
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, nil
+	}
+	sort.Strings(names)
+	return names, err
+}
+
+// hasFiles reports whether there are any files in the directory tree dir.
+func hasFiles(dir string) bool {
+	found := errors.New("found")
+	err := filepath.WalkDir(dir, func(_ string, d fs.DirEntry, err error) error {
+		if err == nil && !d.IsDir() {
+			return found
+		}
+		return err
+	})
+	return err == found
+}
+
+func (s *fsStorage) Tags(name string) ([]string, error) {
+	if _, err := os.Stat(s.repoPath(name, "_manifests")); err != nil {
+		return nil, notExist("repository", name)
+	}
+	entries, err := os.ReadDir(s.repoPath(name, "_tags"))
+	if err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return nil, err
+	}
+	tags := make([]string, 0, len(entries))
+	for _, e := range entries {
+		if !strings.HasPrefix(e.Name(), ".tmp-") {
+			tags = append(tags, e.Name())
+		}
+	}
+	return tags, nil
+}
+
+func (s *fsStorage) GetTag(name, tag string) (string, error) {
+	b, err := os.ReadFile(s.repoPath(name, "_tags", tag))
+	if errors.Is(err, fs.ErrNotExist) {
+		return "", notExist("tag", name+":"+tag)
+	}
+	return string(b), err
+}
+
+func (s *fsStorage) PutTag(name, tag, digest string) error {
+	if _, err := os.Stat(s.linkPath(name, "_manifests", digest)); err != nil {
+		return notExist("manifest", name+"@"+digest)
+	}
+	return writeFileAtomic(s.repoPath(name, "_tags", tag), []byte(digest))
+}
+
+func (s *fsStorage) DeleteTag(name, tag string) error {
+	err := os.Remove(s.repoPath(name, "_tags", tag))
+	if errors.Is(err, fs.ErrNotExist) {
+		return notExist("tag", name+":"+tag)
+	}
+	return err
+}
+
+func (s *fsStorage) GetManifest(name, digest string) (string, []byte, error) {
+	mediaType, err := os.ReadFile(s.linkPath(name, "_manifests", digest))
+	if errors.Is(err, fs.ErrNotExist) {
+		return "", nil, notExist("manifest", name+"@"+digest)
+	}
+	if err != nil {
+		return "", nil, err
+	}
+	raw, err := os.ReadFile(s.blobPath(digest))
+	return string(mediaType), raw, err
+}
+
+func (s *fsStorage) PutManifest(name, digest, mediaType string, raw []byte) error {
+	if err := writeFileAtomic(s.blobPath(digest), raw); err != nil {
+		return err
+	}
+	return writeFileAtomic(s.linkPath(name, "_manifests", digest), []byte(mediaType))
+}
+
+func (s *fsStorage) DeleteManifest(name, digest string) error {
+	err := os.Remove(s.linkPath(name, "_manifests", digest))
+	if errors.Is(err, fs.ErrNotExist) {
+		return notExist("manifest", name+"@"+digest)
+	}
+	if err != nil {
+		return err
+	}
+	tags, err := s.Tags(name)
+	if err != nil {
+		return err
+	}
+	for _, tag := range tags {
+		if d, err := s.GetTag(name, tag); err == nil && d == digest {
+			if err := s.DeleteTag(name, tag); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func (s *fsStorage) StatBlob(name, digest string) (int64, error) {
+	if _, err := os.Stat(s.linkPath(name, "_blobs", digest)); err != nil {
+		return 0, notExist("blob", name+"@"+digest)
+	}
+	fi, err := os.Stat(s.blobPath(digest))
+	if err != nil {
+		return 0, err
+	}
+	return fi.Size(), nil
+}
+
+func (s *fsStorage) OpenBlob(name, digest string) (io.ReadSeekCloser, error) {
+	if _, err := os.Stat(s.linkPath(name, "_blobs", digest)); err != nil {
+		return nil, notExist("blob", name+"@"+digest)
+	}
+	return os.Open(s.blobPath(digest))
+}
+
+func (s *fsStorage) PutBlob(name, digest string, r io.Reader) error {
+	if _, err := os.Stat(s.blobPath(digest)); errors.Is(err, fs.ErrNotExist) {
+		if err := writeAtomic(s.blobPath(digest), r); err != nil {
+			return err
+		}
+	}
+	return writeFileAtomic(s.linkPath(name, "_blobs", digest), nil)
+}
+
+func (s *fsStorage) DeleteBlob(name, digest string) error {
+	err := os.Remove(s.linkPath(name, "_blobs", digest))
+	if errors.Is(err, fs.ErrNotExist) {
+		return notExist("blob", name+"@"+digest)
+	}
+	return err
+}
+
+func (s *fsStorage) StartUpload(id string) error {
+	if err := os.MkdirAll(s.uploadPath(""), 0o755); err != nil {
+		return err
+	}
+	f, err := os.OpenFile(s.uploadPath(id), os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0o644)
+	if err != nil {
+		return err
+	}
+	return f.Close()
+}
+
+func (s *fsStorage) AppendUpload(id string, r io.Reader) (int64, error) {
+	f, err := os.OpenFile(s.uploadPath(id), os.O_WRONLY|os.O_APPEND, 0)
+	if errors.Is(err, fs.ErrNotExist) {
+		return 0, notExist("upload", id)
+	}
+	if err != nil {
+		return 0, err
+	}
+	_, err = io.Copy(f, r)
+	if cerr := f.Close(); err == nil {
+		err = cerr
+	}
+	if err != nil {
+		return 0, err
+	}
+	fi, err := os.Stat(s.uploadPath(id))
+	if err != nil {
+		return 0, err
+	}
+	return fi.Size(), nil
+}
+
+// CommitUpload moves the upload to the blob unless the blob is already
+// stored.
+func (s *fsStorage) CommitUpload(id, name, digest string) error {
+	path := s.uploadPath(id)
+	if _, err := os.Stat(path); errors.Is(err, fs.ErrNotExist) {
+		return notExist("upload", id)
+	}
+	defer os.Remove(path)
+	if _, err := os.Stat(s.blobPath(digest)); errors.Is(err, fs.ErrNotExist) {
+		if err := os.MkdirAll(filepath.Dir(s.blobPath(digest)), 0o755); err != nil {
+			return err
+		}
+		if err := os.Rename(path, s.blobPath(digest)); err != nil {
+			return err
+		}
+	}
+	return writeFileAtomic(s.linkPath(name, "_blobs", digest), nil)
+}
+
+func (s *fsStorage) DeleteUpload(id string) error {
+	err := os.Remove(s.uploadPath(id))
+	if errors.Is(err, fs.ErrNotExist) {
+		return notExist("upload", id)
+	}
+	return err
+}
+
+func writeFileAtomic(path string, b []byte) error {
+	return writeAtomic(path, bytes.NewReader(b))
+}
+
+// writeAtomic writes the contents of r to a temporary file in the
+// directory of path, creating it if needed, and renames it to path so
+// readers never see a partial file.
+func writeAtomic(path string, r io.Reader) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return err
+	}
+	f, err := os.CreateTemp(filepath.Dir(path), ".tmp-*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(f.Name())
+	if _, err := io.Copy(f, r); err != nil {
+		f.Close()
+		return err
+	}
+	if err := f.Close(); err != nil {
+		return err
+	}
+	return os.Rename(f.Name(), path)
+}
+
+func notExist(kind, name string) error {
+	return fmt.Errorf("%s %s: %w", kind, name, fs.ErrNotExist)
+}
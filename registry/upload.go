@@ -0,0 +1,120 @@
+package registry
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"strings"
+
+	"foxygo.at/dreg/pb"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// Default size of chunks for chunked blob uploads. Blobs no larger than the
+// chunk size are uploaded in a single request.
+const DefaultChunkSize = 16 * 1024 * 1024
+
+// PushBlob uploads the blob described by desc to the named repository unless
+// it already exists there. If from is not empty, it first tries to mount the
+// blob from that repository in the same registry. open is called to read the
+// blob contents if it needs to be uploaded.
+func (c *Client) PushBlob(ctx context.Context, name string, desc *pb.Descriptor, from string, open func() (io.ReadCloser, error), chunkSize int) error {
+	exists, err := c.BlobExists(ctx, name, desc.Digest)
+	if err != nil || exists {
+		return err
+	}
+	req := &pb.StartBlobUploadRequest{Name: name}
+	if from != "" {
+		req.Mount = desc.Digest
+		req.From = from
+	}
+	upload, err := c.StartBlobUpload(ctx, req)
+	if err != nil {
+		return err
+	}
+	if upload.Digest != "" && from != "" {
+		// Mounted. There is no upload to continue.
+		return nil
+	}
+	r, err := open()
+	if err != nil {
+		return err
+	}
+	defer r.Close()
+	return c.uploadBlob(ctx, name, upload, desc, r, chunkSize)
+}
+
+// BlobExists returns true if the blob with the given digest exists in the
+// named repository.
+func (c *Client) BlobExists(ctx context.Context, name, digest string) (bool, error) {
+	req := &pb.HeadBlobRequest{Name: name, Digest: digest}
+	_, err := c.HeadBlob(ctx, req)
+	if status.Code(err) == codes.NotFound {
+		return false, nil
+	}
+	return err == nil, err
+}
+
+// uploadBlob uploads desc.Size bytes read from r to the started upload,
+// in chunks of chunkSize bytes. The last chunk, or the whole blob if it is
+// no larger than chunkSize or chunkSize is not positive, is sent when
+// completing the upload. The upload is not completed unless the data read
+// matches the digest and size of desc.
+func (c *Client) uploadBlob(ctx context.Context, name string, upload *pb.StartBlobUploadResponse, desc *pb.Descriptor, r io.Reader, chunkSize int) error {
+	if !strings.HasPrefix(desc.Digest, "sha256:") {
+		return fmt.Errorf("%s: unsupported digest algorithm", desc.Digest)
+	}
+	h := sha256.New()
+	r = io.TeeReader(io.LimitReader(r, int64(desc.Size)), h)
+	location := upload.Location
+	var offset uint64
+	for chunkSize > 0 && desc.Size-offset > uint64(chunkSize) {
+		data := make([]byte, chunkSize)
+		if _, err := io.ReadFull(r, data); err != nil {
+			return fmt.Errorf("%s: cannot read blob: %w", desc.Digest, err)
+		}
+		req := &pb.UploadBlobChunkRequest{
+			Name:     name,
+			Uuid:     upload.Uuid,
+			Location: location,
+			Data:     data,
+			Range:    fmt.Sprintf("%d-%d", offset, offset+uint64(len(data))-1),
+		}
+		resp, err := c.UploadBlobChunk(ctx, req)
+		if err != nil {
+			return err
+		}
+		if resp.Location != "" {
+			location = resp.Location
+		}
+		offset += uint64(len(data))
+	}
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return fmt.Errorf("%s: cannot read blob: %w", desc.Digest, err)
+	}
+	if size := offset + uint64(len(data)); size != desc.Size {
+		return fmt.Errorf("%s: size mismatch: expected %d, got %d", desc.Digest, desc.Size, size)
+	}
+	if digest := "sha256:" + hex.EncodeToString(h.Sum(nil)); digest != desc.Digest {
+		return fmt.Errorf("%s: digest mismatch: got %s", desc.Digest, digest)
+	}
+	req := &pb.CompleteBlobUploadRequest{
+		Name:     name,
+		Uuid:     upload.Uuid,
+		Location: location,
+		Digest:   desc.Digest,
+		Data:     data,
+	}
+	resp, err := c.CompleteBlobUpload(ctx, req)
+	if err != nil {
+		return err
+	}
+	if resp.Digest != "" && resp.Digest != desc.Digest {
+		return fmt.Errorf("%s: registry reported digest %s", desc.Digest, resp.Digest)
+	}
+	return nil
+}
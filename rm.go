@@ -0,0 +1,186 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+
+	"foxygo.at/dreg/pb"
+	"foxygo.at/dreg/registry"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+)
+
+type rm struct {
+	Images []string `arg:"" name:"image" help:"Images to delete from registry, as [registry/]name[:tag] or [registry/]name@digest"`
+	Force  bool     `short:"f" help:"Delete images even if other tags refer to the same manifest, removing those tags too"`
+	Untag  bool     `help:"Delete only the tag if other tags refer to the same manifest, failing if the registry does not support deleting tags"`
+}
+
+// rm.Run executes the rm cli subcommand, deleting images from the registry.
+//
+// A tag is deleted on its own if the registry supports it, and otherwise
+// by deleting the manifest it refers to, which removes all other tags
+// referring to the same manifest. If there are other tags, the image is
+// not deleted unless --untag is set to delete only the tag, or --force to
+// delete the manifest and all its tags. Registries that do not support
+// deleting tags may delete the manifest for a tag rather than fail, so
+// --untag is only safe if the registry is known to support it.
+//
+// If the command is interrupted, a delete request in progress completes
+// and the images removed so far are reported.
+func (r *rm) Run(cfg *config) error {
+	ctx := cfg.context()
+	out := cfg.records()
+	var records []proto.Message
+	var removed []string
+	failed := 0
+	for i, image := range r.Images {
+		record, err := r.remove(ctx, cfg, image)
+		if ctx.Err() != nil && err != nil {
+			reportInterrupted(removed, r.Images[i:])
+			return ctx.Err()
+		}
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Couldn't remove %s: %v\n", image, err)
+			failed++
+			continue
+		}
+		if out != nil {
+			records = append(records, record)
+		} else if cfg.Verbose {
+			fmt.Printf("%s removed\n", image)
+		}
+		removed = append(removed, image)
+		if ctx.Err() != nil && i+1 < len(r.Images) {
+			reportInterrupted(removed, r.Images[i+1:])
+			return ctx.Err()
+		}
+	}
+
+	if out != nil {
+		if err := writeRecords(out, records...); err != nil {
+			return err
+		}
+	}
+	if failed > 0 {
+		return fmt.Errorf("%d image(s) not removed", failed)
+	}
+	return nil
+}
+
+// reportInterrupted reports the images removed and not removed when rm is
+// interrupted.
+func reportInterrupted(removed, notRemoved []string) {
+	fmt.Fprintf(os.Stderr, "Interrupted after removing %d image(s)\n", len(removed))
+	for _, image := range removed {
+		fmt.Fprintf(os.Stderr, "  removed:     %s\n", image)
+	}
+	for _, image := range notRemoved {
+		fmt.Fprintf(os.Stderr, "  not removed: %s\n", image)
+	}
+}
+
+// remove removes image and returns a record of it.
+func (r *rm) remove(ctx context.Context, cfg *config, image string) (*pb.ImageRecord, error) {
+	client, ref, err := cfg.resolveImage(image)
+	if err != nil {
+		return nil, err
+	}
+	name := ref.Path
+	record := &pb.ImageRecord{Repository: ref.Name(), Tag: ref.Tag, Digest: ref.Digest}
+	if ref.Digest != "" {
+		return record, client.Delete(ctx, name, ref.Digest)
+	}
+
+	tag := ref.TagOrDigest()
+	digest, err := client.Resolve(ctx, name, tag)
+	if err != nil {
+		return nil, fmt.Errorf("couldn't find image: %w", err)
+	}
+	record.Digest = digest
+	others, err := sharedTags(ctx, client, name, tag, digest)
+	if err != nil {
+		return nil, err
+	}
+	if len(others) == 0 {
+		return record, deleteTag(ctx, client, name, tag, digest)
+	}
+
+	switch {
+	case r.Untag:
+		if err := untag(ctx, client, name, tag, others); err != nil {
+			return nil, err
+		}
+		return record, nil
+	case r.Force:
+		fmt.Fprintf(os.Stderr, "Removing %s also removes tags: %s\n", image, strings.Join(others, ", "))
+		return record, client.Delete(ctx, name, digest)
+	}
+	return nil, fmt.Errorf("%s is also tagged %s (use --untag to delete only the tag if the registry supports it, or --force to delete all)", digest, strings.Join(others, ", "))
+}
+
+// sharedTags returns the tags of the named repository other than tag that
+// refer to the manifest with the given digest.
+func sharedTags(ctx context.Context, client *registry.Client, name, tag, digest string) ([]string, error) {
+	tags, err := client.Tags(ctx, name)
+	if err != nil {
+		return nil, err
+	}
+	var result []string
+	for _, t := range tags {
+		if t == tag {
+			continue
+		}
+		d, err := client.Resolve(ctx, name, t)
+		if status.Code(err) == codes.NotFound {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		if d == digest {
+			result = append(result, t)
+		}
+	}
+	return result, nil
+}
+
+// deleteTag deletes the tag of the named repository, or the manifest with
+// the given digest that it refers to if the registry does not support
+// deleting tags.
+func deleteTag(ctx context.Context, client *registry.Client, name, tag, digest string) error {
+	err := client.Delete(ctx, name, tag)
+	if code := status.Code(err); code == codes.Unimplemented || code == codes.InvalidArgument {
+		err = client.Delete(ctx, name, digest)
+	}
+	return err
+}
+
+// untag deletes just the tag of the named repository, failing if the
+// registry does not support deleting tags or if it removed any of the other
+// tags kept that refer to the same manifest.
+func untag(ctx context.Context, client *registry.Client, name, tag string, kept []string) error {
+	err := client.Delete(ctx, name, tag)
+	if code := status.Code(err); code == codes.Unimplemented || code == codes.InvalidArgument {
+		return fmt.Errorf("registry does not support deleting tags only: %w", err)
+	}
+	if err != nil {
+		return err
+	}
+	var removed []string
+	for _, t := range kept {
+		_, err := client.GetDigest(ctx, &pb.GetDigestRequest{Name: name, Reference: t})
+		if status.Code(err) == codes.NotFound {
+			removed = append(removed, t)
+		} else if err != nil {
+			return fmt.Errorf("couldn't check tags sharing the manifest were kept: %w", err)
+		}
+	}
+	if len(removed) > 0 {
+		return fmt.Errorf("registry removed %s as well as %s", strings.Join(removed, ", "), tag)
+	}
+	return nil
+}
@@ -0,0 +1,490 @@
+package main
+
+import (
+	"archive/tar"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"time"
+
+	"foxygo.at/dreg/pb"
+	"foxygo.at/dreg/reference"
+	"foxygo.at/dreg/registry"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+)
+
+type save struct {
+	Images    []string `arg:"" help:"Images to save, as [registry/]name[:tag] or [registry/]name@digest"`
+	File      string   `short:"o" type:"path" help:"Write docker save tarball to file (default stdout)"`
+	OCILayout string   `name:"oci-layout" type:"path" help:"Write OCI image layout to directory instead of docker save tarball"`
+	Platform  string   `short:"p" help:"Only save platform os/arch[/variant] of multi-platform images, failing for single-platform images of other platforms (default linux/amd64 of multi-platform images for docker save tarballs)"`
+}
+
+// defaultSavePlatform is the platform of multi-platform images saved to
+// docker save tarballs, which hold a single platform per image.
+const defaultSavePlatform = "linux/amd64"
+
+// save.Run executes the save cli subcommand, writing images from the
+// registry to a docker save tarball that can be loaded with `docker load`
+// or to an OCI image layout directory. Blobs shared by the images are
+// written once. Images saved to an existing OCI image layout are added to
+// its index. Both can be pushed back to a registry with `dreg push`.
+func (s *save) Run(cfg *config) error {
+	ctx := cfg.context()
+	if s.File != "" && s.OCILayout != "" {
+		return errors.New("cannot save to both --file and --oci-layout")
+	}
+	toStdout := s.File == "" && s.OCILayout == ""
+	if toStdout && isTerminal(os.Stdout) {
+		return errors.New("refusing to write tarball to terminal: use --file or redirect stdout")
+	}
+	platform := s.Platform
+	if platform == "" && s.OCILayout == "" {
+		platform = defaultSavePlatform
+	}
+	var filter *pb.Platform
+	if platform != "" {
+		var err error
+		if filter, err = registry.ParsePlatform(platform); err != nil {
+			return err
+		}
+	}
+
+	var w layoutWriter
+	var f *os.File
+	var index []indexDescriptorJSON
+	switch {
+	case s.OCILayout != "":
+		if err := os.MkdirAll(s.OCILayout, 0o777); err != nil {
+			return err
+		}
+		var err error
+		if index, err = readIndex(s.OCILayout); err != nil {
+			return err
+		}
+		w = dirWriter(s.OCILayout)
+	case toStdout:
+		w = newTarWriter(os.Stdout)
+	default:
+		var err error
+		if f, err = os.Create(s.File); err != nil {
+			return err
+		}
+		w = newTarWriter(f)
+	}
+	saver := &imageSaver{w: w, docker: s.OCILayout == "", written: map[string]bool{}, index: index}
+	records, err := s.save(ctx, cfg, saver, filter)
+	if err == nil {
+		err = saver.writeIndex()
+	}
+	if cerr := w.Close(); err == nil {
+		err = cerr
+	}
+	if f != nil {
+		if cerr := f.Close(); err == nil {
+			err = cerr
+		}
+		if err != nil {
+			os.Remove(s.File)
+		}
+	}
+	if err != nil {
+		return err
+	}
+
+	if out := cfg.records(); out != nil && !toStdout {
+		return writeRecords(out, records...)
+	}
+	// Do not mix output with a tarball written to stdout.
+	out := os.Stdout
+	if toStdout {
+		out = os.Stderr
+	}
+	for _, r := range records {
+		r := r.(*pb.ImageRecord)
+		fmt.Fprintf(out, "%s@%s\n", r.Repository, r.Digest)
+	}
+	return nil
+}
+
+// save writes the manifests and blobs of the images and returns a record
+// of each image saved.
+func (s *save) save(ctx context.Context, cfg *config, saver *imageSaver, filter *pb.Platform) ([]proto.Message, error) {
+	var records []proto.Message
+	for _, image := range s.Images {
+		client, ref, err := cfg.resolveImage(image)
+		if err != nil {
+			return nil, err
+		}
+		resp, err := client.Manifest(ctx, ref.Path, ref.TagOrDigest())
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", image, err)
+		}
+		desc := &pb.Descriptor{MediaType: resp.MediaType, Size: uint64(len(resp.Raw)), Digest: resp.Digest}
+		if !registry.IsManifestMediaType(desc.MediaType) {
+			desc.MediaType = registry.ManifestMediaType(resp.Manifest)
+		}
+		if desc.Digest == "" {
+			desc.Digest = registry.SHA256Digest(resp.Raw)
+		}
+		// The default platform only selects from multi-platform images.
+		if filter != nil && (resp.Manifest.GetIndex() != nil || s.Platform != "") {
+			images, err := client.Images(ctx, ref.Path, resp, filter)
+			if err != nil {
+				return nil, fmt.Errorf("%s: %w", image, err)
+			}
+			if len(images) == 0 {
+				return nil, fmt.Errorf("%s: no image for platform %s", image, registry.FormatPlatform(filter))
+			}
+			pi := images[0]
+			desc = &pb.Descriptor{MediaType: pi.MediaType, Size: uint64(len(pi.Raw)), Digest: pi.Digest}
+			if !registry.IsManifestMediaType(desc.MediaType) {
+				desc.MediaType = registry.ManifestMediaType(&pb.Manifest{Manifest: &pb.Manifest_Image{Image: pi.Image}})
+			}
+		}
+		src := &registrySource{client: client, name: ref.Path}
+		if err := saver.saveManifest(ctx, src, desc, ref); err != nil {
+			return nil, fmt.Errorf("%s: %w", image, err)
+		}
+		records = append(records, &pb.ImageRecord{Repository: ref.Name(), Tag: ref.Tag, Digest: desc.Digest, MediaType: desc.MediaType})
+	}
+	return records, nil
+}
+
+// imageSaver writes images from blob sources to an OCI image layout, and
+// for docker save tarballs, the docker manifest.json and repositories
+// files.
+type imageSaver struct {
+	w layoutWriter
+	// docker is set when writing a docker save tarball.
+	docker bool
+	// written is the set of digests of the blobs written.
+	written map[string]bool
+	// index are the descriptors of the saved images for index.json.
+	index []indexDescriptorJSON
+	// dockerManifests are the entries of manifest.json.
+	dockerManifests []dockerSaveManifest
+	// repositories maps image names to tags to the layer IDs of the
+	// legacy repositories file.
+	repositories map[string]map[string]string
+}
+
+// layoutWriter writes files to an image layout.
+type layoutWriter interface {
+	// writeFile writes the contents of r, which are size bytes long, to
+	// the slash-separated path name.
+	writeFile(name string, size int64, r io.Reader) error
+	// hasFile returns true if name has been written with the given size.
+	hasFile(name string, size int64) bool
+	io.Closer
+}
+
+// indexJSON is the JSON encoding of the index.json of an OCI image layout.
+type indexJSON struct {
+	SchemaVersion uint32                `json:"schemaVersion"`
+	MediaType     string                `json:"mediaType"`
+	Manifests     []indexDescriptorJSON `json:"manifests"`
+}
+
+type indexDescriptorJSON struct {
+	descriptorJSON
+	Annotations map[string]string `json:"annotations,omitempty"`
+	// raw is the descriptor as read from an existing index.json, which is
+	// written as is to keep the fields not in indexDescriptorJSON.
+	raw json.RawMessage
+}
+
+func (d indexDescriptorJSON) MarshalJSON() ([]byte, error) {
+	if d.raw != nil {
+		return d.raw, nil
+	}
+	type plain indexDescriptorJSON
+	return json.Marshal(plain(d))
+}
+
+// readIndex returns the manifests of the index.json of the OCI image
+// layout in dir, or none if it has no index.json.
+func readIndex(dir string) ([]indexDescriptorJSON, error) {
+	b, err := os.ReadFile(filepath.Join(dir, "index.json"))
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var index struct {
+		Manifests []json.RawMessage `json:"manifests"`
+	}
+	if err := json.Unmarshal(b, &index); err != nil {
+		return nil, fmt.Errorf("cannot parse index.json: %w", err)
+	}
+	result := make([]indexDescriptorJSON, len(index.Manifests))
+	for i, raw := range index.Manifests {
+		if err := json.Unmarshal(raw, &result[i]); err != nil {
+			return nil, fmt.Errorf("cannot parse index.json: %w", err)
+		}
+		result[i].raw = raw
+	}
+	return result, nil
+}
+
+// addToIndex adds d to the index, replacing a descriptor with the same
+// ref name annotation, and image name annotation if both have one. A
+// descriptor without a ref name replaces one without with the same digest.
+func (is *imageSaver) addToIndex(d indexDescriptorJSON) {
+	refName, imageName := d.Annotations[annotationRefName], d.Annotations[annotationImageName]
+	for i, e := range is.index {
+		if e.Annotations[annotationRefName] != refName {
+			continue
+		}
+		if refName == "" && e.Digest != d.Digest {
+			continue
+		}
+		if name := e.Annotations[annotationImageName]; name != "" && imageName != "" && name != imageName {
+			continue
+		}
+		is.index[i] = d
+		return
+	}
+	is.index = append(is.index, d)
+}
+
+// saveManifest writes the manifest described by desc from src after its
+// blobs or, for an image index, the manifests it references, and adds it
+// to the index as the image ref.
+func (is *imageSaver) saveManifest(ctx context.Context, src blobSource, desc *pb.Descriptor, ref reference.Reference) error {
+	image, err := is.writeManifest(ctx, src, desc)
+	if err != nil {
+		return err
+	}
+	d := indexDescriptorJSON{descriptorJSON: descriptorJSON{desc.MediaType, desc.Size, desc.Digest}}
+	var repoTags []string
+	if ref.Digest == "" {
+		tag := ref.TagOrDigest()
+		d.Annotations = map[string]string{
+			annotationRefName:   tag,
+			annotationImageName: ref.Name() + ":" + tag,
+		}
+		repoTags = []string{ref.Name() + ":" + tag}
+	}
+	is.addToIndex(d)
+	if image == nil {
+		return nil
+	}
+	dsm := dockerSaveManifest{Config: blobPath(image.Config.Digest), RepoTags: repoTags}
+	for _, layer := range image.Layers {
+		dsm.Layers = append(dsm.Layers, blobPath(layer.Digest))
+	}
+	is.dockerManifests = append(is.dockerManifests, dsm)
+	if len(repoTags) > 0 && len(image.Layers) > 0 {
+		if is.repositories == nil {
+			is.repositories = map[string]map[string]string{}
+		}
+		if is.repositories[ref.Name()] == nil {
+			is.repositories[ref.Name()] = map[string]string{}
+		}
+		_, id := registry.SplitDigest(image.Layers[len(image.Layers)-1].Digest)
+		is.repositories[ref.Name()][ref.TagOrDigest()] = id
+	}
+	return nil
+}
+
+// writeManifest writes the manifest described by desc and the blobs and
+// manifests it references. It returns the manifest if it is an image
+// manifest.
+func (is *imageSaver) writeManifest(ctx context.Context, src blobSource, desc *pb.Descriptor) (*pb.ImageManifest, error) {
+	raw, err := src.readManifest(ctx, desc.Digest)
+	if err != nil {
+		return nil, err
+	}
+	m, err := registry.ParseManifest(desc.MediaType, raw)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", desc.Digest, err)
+	}
+	switch m := m.Manifest.(type) {
+	case *pb.Manifest_Image:
+		if err := is.writeBlobs(ctx, src, m.Image); err != nil {
+			return nil, err
+		}
+	case *pb.Manifest_Index:
+		for _, child := range m.Index.Manifests {
+			if _, err := is.writeManifest(ctx, src, child); err != nil {
+				return nil, err
+			}
+		}
+	}
+	if err := is.writeBlob(desc.Digest, int64(len(raw)), bytes.NewReader(raw)); err != nil {
+		return nil, err
+	}
+	return m.GetImage(), nil
+}
+
+// writeBlobs writes the config and layers of image. Non-distributable
+// layers that cannot be fetched from src are skipped in OCI image layouts
+// as they are fetched from their URLs instead. Docker save tarballs must
+// hold all layers, so they fail.
+func (is *imageSaver) writeBlobs(ctx context.Context, src blobSource, image *pb.ImageManifest) error {
+	blobs := append([]*pb.Descriptor{image.Config}, image.Layers...)
+	for _, desc := range blobs {
+		if is.written[desc.Digest] || is.w.hasFile(blobPath(desc.Digest), int64(desc.Size)) {
+			is.written[desc.Digest] = true
+			continue
+		}
+		r, err := src.openBlob(ctx, desc.Digest)
+		if err != nil {
+			if len(desc.Urls) > 0 && status.Code(err) == codes.NotFound {
+				if is.docker {
+					return fmt.Errorf("cannot fetch non-distributable layer %s for docker save tarball, use --oci-layout instead: %w", desc.Digest, err)
+				}
+				continue
+			}
+			return fmt.Errorf("cannot fetch blob %s: %w", desc.Digest, err)
+		}
+		err = is.writeBlob(desc.Digest, int64(desc.Size), r)
+		r.Close()
+		if err != nil {
+			return fmt.Errorf("cannot save blob %s: %w", desc.Digest, err)
+		}
+	}
+	return nil
+}
+
+// writeBlob writes the blob with the given digest unless it has been
+// written already, verifying its contents match the digest.
+func (is *imageSaver) writeBlob(digest string, size int64, r io.Reader) error {
+	if is.written[digest] {
+		return nil
+	}
+	if !digestRE.MatchString(digest) {
+		return fmt.Errorf("invalid digest %q", digest)
+	}
+	if err := is.w.writeFile(blobPath(digest), size, registry.VerifyingReader(r, digest)); err != nil {
+		return err
+	}
+	is.written[digest] = true
+	return nil
+}
+
+// writeIndex writes the oci-layout and index.json files and, for docker
+// save tarballs, the docker manifest.json and repositories files.
+func (is *imageSaver) writeIndex() error {
+	type file struct {
+		name string
+		v    interface{}
+	}
+	files := []file{
+		{"oci-layout", map[string]string{"imageLayoutVersion": "1.0.0"}},
+		{"index.json", indexJSON{SchemaVersion: 2, MediaType: registry.MediaTypeOCIIndex, Manifests: is.index}},
+	}
+	if is.docker {
+		files = append(files, file{"manifest.json", is.dockerManifests})
+		if is.repositories != nil {
+			files = append(files, file{"repositories", is.repositories})
+		}
+	}
+	for _, f := range files {
+		b, err := json.Marshal(f.v)
+		if err != nil {
+			return err
+		}
+		if err := is.w.writeFile(f.name, int64(len(b)), bytes.NewReader(b)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// blobPath returns the path of the blob with the given digest in an OCI
+// image layout.
+func blobPath(digest string) string {
+	alg, hex := registry.SplitDigest(digest)
+	return path.Join("blobs", alg, hex)
+}
+
+// digestRE matches the sha256 digests of blobs that can be saved.
+var digestRE = regexp.MustCompile(`^sha256:[0-9a-f]{64}$`)
+
+// dirWriter writes an OCI image layout to a directory. Files are written
+// atomically so blobs already in the directory, such as from a previous
+// save, can be skipped.
+type dirWriter string
+
+func (d dirWriter) writeFile(name string, size int64, r io.Reader) error {
+	filename := filepath.Join(string(d), filepath.FromSlash(name))
+	if err := os.MkdirAll(filepath.Dir(filename), 0o777); err != nil {
+		return err
+	}
+	f, err := os.CreateTemp(filepath.Dir(filename), ".tmp-")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(f.Name())
+	n, err := io.Copy(f, r)
+	if cerr := f.Close(); err == nil {
+		err = cerr
+	}
+	if err == nil && n != size {
+		err = fmt.Errorf("%s: expected %d bytes, got %d", name, size, n)
+	}
+	if err != nil {
+		return err
+	}
+	return os.Rename(f.Name(), filename)
+}
+
+func (d dirWriter) hasFile(name string, size int64) bool {
+	fi, err := os.Stat(filepath.Join(string(d), filepath.FromSlash(name)))
+	return err == nil && fi.Mode().IsRegular() && fi.Size() == size
+}
+
+func (d dirWriter) Close() error {
+	return nil
+}
+
+// tarWriter writes an image layout to an uncompressed tar file.
+type tarWriter struct {
+	tw *tar.Writer
+}
+
+func newTarWriter(w io.Writer) *tarWriter {
+	return &tarWriter{tw: tar.NewWriter(w)}
+}
+
+func (t *tarWriter) writeFile(name string, size int64, r io.Reader) error {
+	hdr := &tar.Header{
+		Typeflag: tar.TypeReg,
+		Name:     name,
+		Size:     size,
+		Mode:     0o644,
+		ModTime:  time.Unix(0, 0),
+		Format:   tar.FormatPAX,
+	}
+	if err := t.tw.WriteHeader(hdr); err != nil {
+		return err
+	}
+	n, err := io.Copy(t.tw, r)
+	if err == nil && n != size {
+		err = fmt.Errorf("%s: expected %d bytes, got %d", name, size, n)
+	}
+	return err
+}
+
+// hasFile returns false as blobs are deduplicated by imageSaver.
+func (t *tarWriter) hasFile(string, int64) bool {
+	return false
+}
+
+// Close writes the end of the tar file. It does not close the underlying
+// writer.
+func (t *tarWriter) Close() error {
+	return t.tw.Close()
+}
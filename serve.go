@@ -0,0 +1,93 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"time"
+
+	"foxygo.at/dreg/reference"
+	"foxygo.at/dreg/registry"
+)
+
+type serve struct {
+	Listen string `default:"localhost:5000" help:"Address to listen on"`
+	Dir    string `type:"path" help:"Directory to store images in (in memory if not set)"`
+}
+
+type proxy struct {
+	Upstream string        `required:"" help:"URL of registry to proxy, such as https://registry-1.docker.io"`
+	Listen   string        `default:"localhost:5000" help:"Address to listen on"`
+	Dir      string        `type:"path" help:"Directory to store images in (in memory if not set)"`
+	TTL      time.Duration `name:"ttl" default:"5m" help:"Time after which tags are checked with upstream again"`
+}
+
+// serve.Run executes the serve cli subcommand, serving a registry until
+// interrupted.
+func (s *serve) Run(cfg *config) error {
+	storage, err := newStorage(s.Dir)
+	if err != nil {
+		return err
+	}
+	return listenAndServe(cfg.context(), s.Listen, registry.NewHandler(registry.NewServer(storage)))
+}
+
+// proxy.Run executes the proxy cli subcommand, serving a pull-through
+// cache of the upstream registry until interrupted.
+func (p *proxy) Run(cfg *config) error {
+	storage, err := newStorage(p.Dir)
+	if err != nil {
+		return err
+	}
+	upstream, err := registry.Dial(p.Upstream, cfg.clientOptions()...)
+	if err != nil {
+		return err
+	}
+	u, err := url.Parse(p.Upstream)
+	if err != nil {
+		return err
+	}
+	srv := registry.NewProxy(upstream, storage, p.TTL)
+	srv.DockerHub = reference.Reference{Domain: u.Host}.Registry() == reference.DockerHubRegistry
+	return listenAndServe(cfg.context(), p.Listen, registry.NewHandler(srv))
+}
+
+// newStorage returns storage in dir, or in memory if dir is empty.
+func newStorage(dir string) (registry.Storage, error) {
+	if dir == "" {
+		return registry.NewMemStorage(), nil
+	}
+	return registry.NewFSStorage(dir)
+}
+
+// listenAndServe serves h on addr until ctx is done. In-progress requests
+// are then given a few seconds to complete.
+func listenAndServe(ctx context.Context, addr string, h http.Handler) error {
+	l, err := net.Listen("tcp", addr)
+	if err != nil {
+		return err
+	}
+	srv := &http.Server{Handler: h}
+	fmt.Fprintf(os.Stderr, "Serving registry on http://%s\n", l.Addr())
+
+	errc := make(chan error, 1)
+	go func() { errc <- srv.Serve(l) }()
+	select {
+	case err := <-errc:
+		return err
+	case <-ctx.Done():
+	}
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if err := srv.Shutdown(shutdownCtx); err != nil {
+		return err
+	}
+	if err := <-errc; !errors.Is(err, http.ErrServerClosed) {
+		return err
+	}
+	return nil
+}
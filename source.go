@@ -0,0 +1,386 @@
+package main
+
+import (
+	"archive/tar"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"foxygo.at/dreg/pb"
+	"foxygo.at/dreg/reference"
+	"foxygo.at/dreg/registry"
+	"google.golang.org/protobuf/encoding/protojson"
+)
+
+// Media types of docker images saved with `docker save`.
+const (
+	mediaTypeDockerConfig            = "application/vnd.docker.container.image.v1+json"
+	mediaTypeDockerUncompressedLayer = "application/vnd.docker.image.rootfs.diff.tar"
+)
+
+// Annotations identifying images in an OCI image layout.
+// https://github.com/opencontainers/image-spec/blob/main/annotations.md
+const (
+	annotationRefName = "org.opencontainers.image.ref.name"
+	// Set by containerd and docker to the full image name.
+	annotationImageName = "io.containerd.image.name"
+)
+
+// imageSource is a set of images read from an OCI image layout directory or
+// a `docker save` tarball.
+//
+// https://github.com/opencontainers/image-spec/blob/main/image-layout.md
+type imageSource struct {
+	// manifests are the descriptors of the top-level manifests of the
+	// source, as listed in its index.json.
+	manifests []*pb.Descriptor
+	store     blobStore
+	// paths maps digests to paths in store for blobs not stored by
+	// digest under blobs/, as in docker save tarballs.
+	paths map[string]string
+	// generated holds blobs that are generated rather than stored, such
+	// as the manifests of docker save tarballs.
+	generated map[string][]byte
+}
+
+// blobStore opens files by slash-separated path relative to the root of an
+// image source.
+type blobStore interface {
+	open(name string) (io.ReadCloser, error)
+	io.Closer
+}
+
+type dirStore string
+
+// tarStore reads files from an uncompressed tar file, using an index of the
+// offsets of the files in the tar file.
+type tarStore struct {
+	f     *os.File
+	files map[string]*io.SectionReader
+}
+
+// dockerSaveManifest is an entry in the manifest.json of a docker save
+// tarball.
+type dockerSaveManifest struct {
+	Config   string
+	RepoTags []string
+	Layers   []string
+}
+
+// openImageSource opens the OCI image layout directory or docker save
+// tarball at filename.
+func openImageSource(filename string) (*imageSource, error) {
+	fi, err := os.Stat(filename)
+	if err != nil {
+		return nil, err
+	}
+	var store blobStore = dirStore(filename)
+	if !fi.IsDir() {
+		if store, err = openTarStore(filename); err != nil {
+			return nil, err
+		}
+	}
+	src := &imageSource{store: store, paths: map[string]string{}, generated: map[string][]byte{}}
+	if err := src.load(); err != nil {
+		store.Close()
+		return nil, fmt.Errorf("%s: %w", filename, err)
+	}
+	return src, nil
+}
+
+func (s *imageSource) Close() error {
+	return s.store.Close()
+}
+
+// load reads index.json if the source is an OCI image layout, or otherwise
+// manifest.json if it is a docker save tarball.
+func (s *imageSource) load() error {
+	b, err := s.readFile("index.json")
+	if errors.Is(err, os.ErrNotExist) {
+		return s.loadDockerSave()
+	}
+	if err != nil {
+		return err
+	}
+	index := &pb.ImageIndex{}
+	if err := (protojson.UnmarshalOptions{DiscardUnknown: true}).Unmarshal(b, index); err != nil {
+		return fmt.Errorf("cannot parse index.json: %w", err)
+	}
+	s.manifests = index.Manifests
+	return nil
+}
+
+// loadDockerSave generates a docker image manifest for each image in the
+// manifest.json of a docker save tarball, using the uncompressed layers
+// as is.
+func (s *imageSource) loadDockerSave() error {
+	b, err := s.readFile("manifest.json")
+	if err != nil {
+		return fmt.Errorf("not an OCI image layout or docker save tarball: %w", err)
+	}
+	var dsms []dockerSaveManifest
+	if err := json.Unmarshal(b, &dsms); err != nil {
+		return fmt.Errorf("cannot parse manifest.json: %w", err)
+	}
+	for _, dsm := range dsms {
+		image := &pb.ImageManifest{SchemaVersion: 2, MediaType: registry.MediaTypeDockerManifest}
+		if image.Config, err = s.describeFile(dsm.Config, mediaTypeDockerConfig); err != nil {
+			return err
+		}
+		for _, layer := range dsm.Layers {
+			desc, err := s.describeFile(layer, mediaTypeDockerUncompressedLayer)
+			if err != nil {
+				return err
+			}
+			image.Layers = append(image.Layers, desc)
+		}
+		raw, err := marshalManifest(image)
+		if err != nil {
+			return err
+		}
+		desc := &pb.Descriptor{
+			MediaType: registry.MediaTypeDockerManifest,
+			Size:      uint64(len(raw)),
+			Digest:    registry.SHA256Digest(raw),
+		}
+		if len(dsm.RepoTags) > 0 {
+			ref, err := reference.Parse(dsm.RepoTags[0])
+			if err != nil {
+				return fmt.Errorf("invalid tag in manifest.json: %w", err)
+			}
+			desc.Annotations = map[string]string{
+				annotationRefName:   ref.TagOrDigest(),
+				annotationImageName: dsm.RepoTags[0],
+			}
+		}
+		s.generated[desc.Digest] = raw
+		s.manifests = append(s.manifests, desc)
+	}
+	return nil
+}
+
+// describeFile returns a descriptor for the file at name in the source,
+// computing its digest by reading it.
+func (s *imageSource) describeFile(name, mediaType string) (*pb.Descriptor, error) {
+	r, err := s.store.open(name)
+	if err != nil {
+		return nil, err
+	}
+	defer r.Close()
+	h := sha256.New()
+	n, err := io.Copy(h, r)
+	if err != nil {
+		return nil, fmt.Errorf("cannot read %s: %w", name, err)
+	}
+	digest := "sha256:" + hex.EncodeToString(h.Sum(nil))
+	desc := &pb.Descriptor{MediaType: mediaType, Size: uint64(n), Digest: digest}
+	s.paths[desc.Digest] = name
+	return desc, nil
+}
+
+// open opens the blob with the given digest.
+func (s *imageSource) open(digest string) (io.ReadCloser, error) {
+	if b, ok := s.generated[digest]; ok {
+		return io.NopCloser(strings.NewReader(string(b))), nil
+	}
+	if p, ok := s.paths[digest]; ok {
+		return s.store.open(p)
+	}
+	alg, hex := registry.SplitDigest(digest)
+	if alg == "" || hex == "" || strings.ContainsAny(digest, "/\\") {
+		return nil, fmt.Errorf("invalid digest %q", digest)
+	}
+	return s.store.open(path.Join("blobs", alg, hex))
+}
+
+// readBlob reads the blob with the given digest and verifies its contents
+// match the digest.
+func (s *imageSource) readBlob(digest string) ([]byte, error) {
+	r, err := s.open(digest)
+	if err != nil {
+		return nil, err
+	}
+	defer r.Close()
+	b, err := io.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+	if err := registry.VerifyDigest(digest, b); err != nil {
+		return nil, fmt.Errorf("%s: %w", digest, err)
+	}
+	return b, nil
+}
+
+func (s *imageSource) readFile(name string) ([]byte, error) {
+	r, err := s.store.open(name)
+	if err != nil {
+		return nil, err
+	}
+	defer r.Close()
+	return io.ReadAll(r)
+}
+
+// findManifest returns the descriptor of the manifest in the source for the
+// image ref. If the source contains a single manifest, that is returned,
+// otherwise the manifest is selected by its image name annotation, ignoring
+// the registry, or failing that, its ref name annotation.
+func (s *imageSource) findManifest(ref reference.Reference) (*pb.Descriptor, error) {
+	if len(s.manifests) == 1 {
+		return s.manifests[0], nil
+	}
+	tag := ref.TagOrDigest()
+	var refs []string
+	for _, desc := range s.manifests {
+		refName := desc.Annotations[annotationRefName]
+		imageName := desc.Annotations[annotationImageName]
+		if imageName == "" {
+			if refName == tag {
+				return desc, nil
+			}
+			refs = append(refs, refName)
+			continue
+		}
+		if r, err := reference.Parse(imageName); err == nil && r.Path == ref.Path && r.TagOrDigest() == tag {
+			return desc, nil
+		}
+		refs = append(refs, imageName)
+	}
+	return nil, fmt.Errorf("no image %s:%s in source, found: %s", ref.Path, tag, strings.Join(refs, ", "))
+}
+
+func (d dirStore) open(name string) (io.ReadCloser, error) {
+	return os.Open(filepath.Join(string(d), filepath.FromSlash(name)))
+}
+
+func (d dirStore) Close() error {
+	return nil
+}
+
+// maxTarLinks is the maximum number of symlinks and hardlinks followed to
+// resolve a file in a tar file, as for symlink loops.
+const maxTarLinks = 40
+
+// openTarStore opens the tar file filename and indexes the offsets of the
+// regular files in it. Symlinks and hardlinks to regular files, such as the
+// layer.tar links to blobs in docker save tarballs, are indexed as the
+// files they link to.
+func openTarStore(filename string) (*tarStore, error) {
+	f, err := os.Open(filename)
+	if err != nil {
+		return nil, err
+	}
+	ts := &tarStore{f: f, files: map[string]*io.SectionReader{}}
+	// links maps the names of links to the names of their targets.
+	links := map[string]string{}
+	cr := &countingReader{r: f}
+	tr := tar.NewReader(cr)
+	for {
+		hdr, err := tr.Next()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			f.Close()
+			return nil, fmt.Errorf("%s: cannot read tar file: %w", filename, err)
+		}
+		name := tarName(hdr.Name)
+		switch hdr.Typeflag {
+		case tar.TypeReg:
+			ts.files[name] = io.NewSectionReader(f, cr.n, hdr.Size)
+		case tar.TypeLink:
+			links[name] = tarName(hdr.Linkname)
+		case tar.TypeSymlink:
+			target := hdr.Linkname
+			if !path.IsAbs(target) {
+				target = path.Join(path.Dir(name), target)
+			}
+			links[name] = tarName(target)
+		}
+	}
+	for name, target := range links {
+		for i := 0; i < maxTarLinks; i++ {
+			if sr, ok := ts.files[target]; ok {
+				ts.files[name] = sr
+				break
+			}
+			if target = links[target]; target == "" {
+				break
+			}
+		}
+	}
+	return ts, nil
+}
+
+// tarName returns the slash-separated path of a tar file entry name
+// relative to the root of the tar file.
+func tarName(name string) string {
+	return strings.TrimPrefix(path.Clean("/"+name), "/")
+}
+
+func (ts *tarStore) open(name string) (io.ReadCloser, error) {
+	sr, ok := ts.files[name]
+	if !ok {
+		return nil, fmt.Errorf("%s: %w", name, os.ErrNotExist)
+	}
+	return io.NopCloser(io.NewSectionReader(sr, 0, sr.Size())), nil
+}
+
+func (ts *tarStore) Close() error {
+	return ts.f.Close()
+}
+
+// countingReader tracks the offset of a file as it is read or seeked.
+type countingReader struct {
+	r io.ReadSeeker
+	n int64
+}
+
+func (c *countingReader) Read(p []byte) (int, error) {
+	n, err := c.r.Read(p)
+	c.n += int64(n)
+	return n, err
+}
+
+// Seek allows the tar reader to skip file contents without reading them.
+func (c *countingReader) Seek(offset int64, whence int) (int64, error) {
+	n, err := c.r.Seek(offset, whence)
+	if err == nil {
+		c.n = n
+	}
+	return n, err
+}
+
+// manifestJSON is the JSON encoding of the image manifests generated for
+// docker save tarballs. protojson is not used as it encodes sizes as
+// strings.
+type manifestJSON struct {
+	SchemaVersion uint32           `json:"schemaVersion"`
+	MediaType     string           `json:"mediaType"`
+	Config        descriptorJSON   `json:"config"`
+	Layers        []descriptorJSON `json:"layers"`
+}
+
+type descriptorJSON struct {
+	MediaType string `json:"mediaType"`
+	Size      uint64 `json:"size"`
+	Digest    string `json:"digest"`
+}
+
+func marshalManifest(image *pb.ImageManifest) ([]byte, error) {
+	m := manifestJSON{
+		SchemaVersion: image.SchemaVersion,
+		MediaType:     image.MediaType,
+		Config:        descriptorJSON{image.Config.MediaType, image.Config.Size, image.Config.Digest},
+	}
+	for _, l := range image.Layers {
+		m.Layers = append(m.Layers, descriptorJSON{l.MediaType, l.Size, l.Digest})
+	}
+	return json.MarshalIndent(m, "", "   ")
+}
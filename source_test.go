@@ -0,0 +1,69 @@
+package main
+
+import (
+	"archive/tar"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestTarStoreLinks(t *testing.T) {
+	filename := filepath.Join(t.TempDir(), "image.tar")
+	f, err := os.Create(filename)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tw := tar.NewWriter(f)
+	entries := []tar.Header{
+		{Name: "blobs/", Typeflag: tar.TypeDir},
+		{Name: "./blobs/sha256/abc", Typeflag: tar.TypeReg, Size: 4},
+		{Name: "layer/layer.tar", Typeflag: tar.TypeSymlink, Linkname: "../blobs/sha256/abc"},
+		{Name: "abs.tar", Typeflag: tar.TypeSymlink, Linkname: "/blobs/sha256/abc"},
+		{Name: "hard.tar", Typeflag: tar.TypeLink, Linkname: "./blobs/sha256/abc"},
+		{Name: "chain.tar", Typeflag: tar.TypeSymlink, Linkname: "layer/layer.tar"},
+		{Name: "missing.tar", Typeflag: tar.TypeSymlink, Linkname: "blobs/sha256/def"},
+		{Name: "loop1", Typeflag: tar.TypeSymlink, Linkname: "loop2"},
+		{Name: "loop2", Typeflag: tar.TypeSymlink, Linkname: "loop1"},
+	}
+	for _, hdr := range entries {
+		hdr := hdr
+		if err := tw.WriteHeader(&hdr); err != nil {
+			t.Fatal(err)
+		}
+		if hdr.Typeflag == tar.TypeReg {
+			if _, err := tw.Write([]byte("blob")); err != nil {
+				t.Fatal(err)
+			}
+		}
+	}
+	if err := tw.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if err := f.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	ts, err := openTarStore(filename)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer ts.Close()
+	for _, name := range []string{"blobs/sha256/abc", "layer/layer.tar", "abs.tar", "hard.tar", "chain.tar"} {
+		r, err := ts.open(name)
+		if err != nil {
+			t.Errorf("%s: %v", name, err)
+			continue
+		}
+		b, err := io.ReadAll(r)
+		if err != nil || string(b) != "blob" {
+			t.Errorf("%s: got %q, %v, want %q", name, b, err, "blob")
+		}
+	}
+	for _, name := range []string{"blobs", "missing.tar", "loop1"} {
+		if _, err := ts.open(name); !errors.Is(err, os.ErrNotExist) {
+			t.Errorf("%s: got error %v, want not exist", name, err)
+		}
+	}
+}
@@ -0,0 +1,358 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"regexp"
+	"strings"
+	"sync"
+	"text/tabwriter"
+
+	"foxygo.at/dreg/pb"
+	"foxygo.at/dreg/reference"
+	"foxygo.at/dreg/registry"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+)
+
+// syncCmd is the sync command. It is not named sync as that would clash
+// with the sync package.
+type syncCmd struct {
+	Repositories []string `arg:"" optional:"" name:"repository" help:"Repositories to sync (default all repositories of --from)"`
+	From         string   `required:"" help:"URL of registry to sync from"`
+	To           string   `required:"" help:"URL of registry to sync to"`
+	IncludeRepo  string   `help:"Only sync repositories matching this regexp"`
+	ExcludeRepo  string   `help:"Never sync repositories matching this regexp"`
+	IncludeTag   string   `help:"Only sync tags matching this regexp"`
+	ExcludeTag   string   `help:"Never sync tags matching this regexp"`
+	Delete       bool     `help:"Delete tags of synced repositories that are not in --from"`
+	DryRun       bool     `help:"Only show what would be copied and deleted"`
+	Concurrency  int      `default:"4" help:"Maximum number of tags to sync at once"`
+	ChunkSize    int      `default:"16777216" help:"Size of blob upload chunks in bytes (0 to upload blobs in one request)"`
+	PageSize     int32    `help:"Number of repositories or tags to request per page (0 for registry default)"`
+}
+
+// syncFilter selects the repositories and tags to sync.
+type syncFilter struct {
+	includeRepo, excludeRepo *regexp.Regexp
+	includeTag, excludeTag   *regexp.Regexp
+}
+
+// syncTask is a tag to copy or delete.
+type syncTask struct {
+	repo   int
+	tag    string
+	delete bool
+}
+
+// syncSummary is the outcome of syncing a repository.
+type syncSummary struct {
+	repo    string
+	copied  int
+	skipped int
+	deleted int
+	failed  int
+}
+
+// syncCmd.Run executes the sync cli subcommand, copying the tags of
+// repositories in one registry to another. Tags that refer to the same
+// digest in both registries are skipped, so only new and changed tags are
+// copied.
+func (s *syncCmd) Run(cfg *config) error {
+	ctx := cfg.context()
+	filter, err := s.filter()
+	if err != nil {
+		return err
+	}
+	from, err := cfg.newClient(s.From)
+	if err != nil {
+		return err
+	}
+	to, err := cfg.newClient(s.To)
+	if err != nil {
+		return err
+	}
+
+	repos, err := s.repos(ctx, from, filter)
+	if err != nil {
+		return err
+	}
+	srcTags, err := listTags(ctx, repeatClient(from, len(repos)), repos, s.PageSize, 0, s.Concurrency)
+	if err != nil {
+		return err
+	}
+	var dstTags [][]string
+	if s.Delete {
+		if dstTags, err = s.listDstTags(ctx, to, repos); err != nil {
+			return err
+		}
+	}
+
+	// Tags are copied before any are deleted so that a digest that is
+	// still tagged in --from is not deleted with a tag that is not.
+	var copies, deletes []syncTask
+	for i := range repos {
+		src := map[string]bool{}
+		for _, tag := range srcTags[i] {
+			if filter.tag(tag) {
+				src[tag] = true
+				copies = append(copies, syncTask{repo: i, tag: tag})
+			}
+		}
+		if s.Delete {
+			for _, tag := range dstTags[i] {
+				if filter.tag(tag) && !src[tag] {
+					deletes = append(deletes, syncTask{repo: i, tag: tag, delete: true})
+				}
+			}
+		}
+	}
+
+	summaries := make([]syncSummary, len(repos))
+	for i, repo := range repos {
+		summaries[i].repo = repo.Path
+	}
+	out := cfg.records()
+	var records []proto.Message
+	var mu sync.Mutex
+	record := func(t syncTask, action, digest, verb string, err error, counter func(*syncSummary) *int) {
+		mu.Lock()
+		defer mu.Unlock()
+		if out != nil {
+			r := &pb.SyncRecord{Repository: repos[t.repo].Name(), Tag: t.tag, Digest: digest, Action: action}
+			if err != nil {
+				r.Error = err.Error()
+			}
+			records = append(records, r)
+		}
+		name := repos[t.repo].Path + ":" + t.tag
+		if err != nil {
+			summaries[t.repo].failed++
+			fmt.Fprintf(os.Stderr, "Failed to %s %s: %v\n", action, name, err)
+			return
+		}
+		*counter(&summaries[t.repo])++
+		if verb != "" && out == nil {
+			fmt.Printf("%s %s\n", verb, name)
+		}
+	}
+
+	run := func(tasks []syncTask) error {
+		p := newProgress("Syncing tags", len(tasks))
+		defer p.clear()
+		return parallel(ctx, len(tasks), s.Concurrency, func(ctx context.Context, i int) error {
+			t := tasks[i]
+			name := repos[t.repo].Path
+			if t.delete {
+				digest, err := s.deleteTag(ctx, to, name, t.tag)
+				verb := "Deleted"
+				if s.DryRun {
+					verb = "Would delete"
+				}
+				record(t, "delete", digest, verb, err, func(s *syncSummary) *int { return &s.deleted })
+				return nil
+			}
+			copied, digest, err := s.copyTag(ctx, from, to, name, t.tag)
+			switch {
+			case err != nil:
+				record(t, "copy", digest, "", err, nil)
+			case copied && s.DryRun:
+				record(t, "copy", digest, "Would copy", nil, func(s *syncSummary) *int { return &s.copied })
+			case copied:
+				record(t, "copy", digest, "Copied", nil, func(s *syncSummary) *int { return &s.copied })
+			default:
+				record(t, "unchanged", digest, "", nil, func(s *syncSummary) *int { return &s.skipped })
+			}
+			return nil
+		}, p.inc)
+	}
+	if err := run(copies); err != nil {
+		return err
+	}
+	if err := run(deletes); err != nil {
+		return err
+	}
+
+	if out != nil {
+		if err := writeRecords(out, records...); err != nil {
+			return err
+		}
+	} else {
+		fmt.Println()
+		printSyncSummaries(os.Stdout, summaries, s.DryRun)
+	}
+	failed := 0
+	for _, summary := range summaries {
+		failed += summary.failed
+	}
+	if failed > 0 {
+		return fmt.Errorf("%d tags failed to sync", failed)
+	}
+	return nil
+}
+
+func (s *syncCmd) filter() (*syncFilter, error) {
+	f := &syncFilter{}
+	for _, re := range []struct {
+		flag, expr string
+		re         **regexp.Regexp
+	}{
+		{"--include-repo", s.IncludeRepo, &f.includeRepo},
+		{"--exclude-repo", s.ExcludeRepo, &f.excludeRepo},
+		{"--include-tag", s.IncludeTag, &f.includeTag},
+		{"--exclude-tag", s.ExcludeTag, &f.excludeTag},
+	} {
+		if re.expr == "" {
+			continue
+		}
+		var err error
+		if *re.re, err = regexp.Compile(re.expr); err != nil {
+			return nil, fmt.Errorf("invalid %s: %w", re.flag, err)
+		}
+	}
+	return f, nil
+}
+
+func (f *syncFilter) repo(name string) bool {
+	return matches(f.includeRepo, f.excludeRepo, name)
+}
+
+func (f *syncFilter) tag(tag string) bool {
+	return matches(f.includeTag, f.excludeTag, tag)
+}
+
+// matches returns true if s matches include, or include is nil, and does
+// not match exclude.
+func matches(include, exclude *regexp.Regexp, s string) bool {
+	return (include == nil || include.MatchString(s)) && (exclude == nil || !exclude.MatchString(s))
+}
+
+// repos returns the repositories to sync: those given as arguments or all
+// repositories of the --from registry, that match the repository filters.
+func (s *syncCmd) repos(ctx context.Context, from *registry.Client, filter *syncFilter) ([]reference.Reference, error) {
+	var repos []reference.Reference
+	if len(s.Repositories) > 0 {
+		for _, name := range s.Repositories {
+			ref, err := reference.Parse(name)
+			if err != nil {
+				return nil, err
+			}
+			if ref.Domain != "" || ref.Tag != "" || ref.Digest != "" {
+				return nil, fmt.Errorf("%s: repositories to sync must be names in --from without registry, tag or digest", name)
+			}
+			repos = append(repos, ref)
+		}
+	} else {
+		var err error
+		if repos, err = catalog(ctx, from, s.PageSize); err != nil {
+			return nil, err
+		}
+	}
+	var result []reference.Reference
+	for _, repo := range repos {
+		if filter.repo(repo.Path) {
+			result = append(result, repo)
+		}
+	}
+	return result, nil
+}
+
+// listDstTags returns the tags of repos in the --to registry. Repositories
+// not in the registry have no tags.
+func (s *syncCmd) listDstTags(ctx context.Context, to *registry.Client, repos []reference.Reference) ([][]string, error) {
+	tags := make([][]string, len(repos))
+	err := parallel(ctx, len(repos), s.Concurrency, func(ctx context.Context, i int) error {
+		t, err := to.TagIterator(repos[i].Path, s.PageSize).Collect(ctx, 0)
+		if status.Code(err) == codes.NotFound {
+			return nil
+		}
+		tags[i] = t
+		return err
+	}, nil)
+	return tags, err
+}
+
+// copyTag copies the image name:tag from one registry to the other unless
+// the tag refers to the same digest in both. It reports whether the image
+// was copied, or would be for a dry run, and returns its digest.
+func (s *syncCmd) copyTag(ctx context.Context, from, to *registry.Client, name, tag string) (bool, string, error) {
+	digest, err := from.Resolve(ctx, name, tag)
+	if err != nil {
+		return false, "", err
+	}
+	dstDigest, err := to.Resolve(ctx, name, tag)
+	if err != nil && status.Code(err) != codes.NotFound {
+		return false, digest, err
+	}
+	if dstDigest == digest {
+		return false, digest, nil
+	}
+	if s.DryRun {
+		return true, digest, nil
+	}
+	pusher := &imagePusher{
+		client:    to,
+		src:       &registrySource{client: from, name: name},
+		name:      name,
+		chunkSize: s.ChunkSize,
+	}
+	_, _, err = copyImage(ctx, pusher, digest, tag)
+	return err == nil, digest, err
+}
+
+// deleteTag deletes name:tag from the --to registry and returns the digest
+// it referred to. If other tags in the --to registry refer to the same
+// digest, only the tag is deleted and it fails if the registry does not
+// support that or removed the other tags too. Otherwise, if the registry
+// does not support deleting tags, the image is deleted by digest.
+func (s *syncCmd) deleteTag(ctx context.Context, to *registry.Client, name, tag string) (string, error) {
+	digest, err := to.Resolve(ctx, name, tag)
+	if err != nil {
+		return "", err
+	}
+	others, err := sharedTags(ctx, to, name, tag, digest)
+	if err != nil {
+		return digest, err
+	}
+	if s.DryRun {
+		return digest, nil
+	}
+	if len(others) > 0 {
+		if err := untag(ctx, to, name, tag, others); err != nil {
+			return digest, fmt.Errorf("%s is also tagged %s: %w", digest, strings.Join(others, ", "), err)
+		}
+		return digest, nil
+	}
+	return digest, deleteTag(ctx, to, name, tag, digest)
+}
+
+func printSyncSummaries(out io.Writer, summaries []syncSummary, dryRun bool) {
+	w := tabwriter.NewWriter(out, 0, 0, 2, ' ', 0)
+	copied, deleted := "COPIED", "DELETED"
+	if dryRun {
+		copied, deleted = "TO COPY", "TO DELETE"
+	}
+	fmt.Fprintf(w, "REPOSITORY\t%s\tUNCHANGED\t%s\tFAILED\n", copied, deleted)
+	var total syncSummary
+	for _, s := range summaries {
+		fmt.Fprintf(w, "%s\t%d\t%d\t%d\t%d\n", s.repo, s.copied, s.skipped, s.deleted, s.failed)
+		total.copied += s.copied
+		total.skipped += s.skipped
+		total.deleted += s.deleted
+		total.failed += s.failed
+	}
+	fmt.Fprintf(w, "TOTAL\t%d\t%d\t%d\t%d\n", total.copied, total.skipped, total.deleted, total.failed)
+	w.Flush()
+}
+
+// repeatClient returns a slice of n copies of client.
+func repeatClient(client *registry.Client, n int) []*registry.Client {
+	clients := make([]*registry.Client, n)
+	for i := range clients {
+		clients[i] = client
+	}
+	return clients
+}
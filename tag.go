@@ -0,0 +1,57 @@
+package main
+
+import (
+	"fmt"
+
+	"foxygo.at/dreg/pb"
+	"foxygo.at/dreg/registry"
+	"google.golang.org/protobuf/proto"
+)
+
+type tag struct {
+	Image string   `arg:"" help:"Image to tag, as [registry/]name[:tag] or [registry/]name@digest"`
+	Tags  []string `arg:"" name:"tag" help:"Tags to add to image"`
+}
+
+// tag.Run executes the tag cli subcommand, adding tags to an image in the
+// registry. The manifest is put under each tag exactly as it was fetched,
+// so the tags refer to the same digest.
+func (t *tag) Run(cfg *config) error {
+	ctx := cfg.context()
+	client, ref, err := cfg.resolveImage(t.Image)
+	if err != nil {
+		return err
+	}
+	name := ref.Path
+	resp, err := client.Manifest(ctx, name, ref.TagOrDigest())
+	if err != nil {
+		return err
+	}
+	mediaType := resp.MediaType
+	if !registry.IsManifestMediaType(mediaType) {
+		mediaType = registry.ManifestMediaType(resp.Manifest)
+	}
+	digest := resp.Digest
+
+	out := cfg.records()
+	var records []proto.Message
+	for _, tag := range t.Tags {
+		req := &pb.PutManifestRequest{Name: name, Reference: tag, MediaType: mediaType, Raw: resp.Raw}
+		resp, err := client.PutManifest(ctx, req)
+		if err != nil {
+			return err
+		}
+		if resp.Digest != "" && resp.Digest != digest {
+			return fmt.Errorf("%s:%s: registry reported digest %s, expected %s", name, tag, resp.Digest, digest)
+		}
+		if out != nil {
+			records = append(records, &pb.ImageRecord{Repository: ref.Name(), Tag: tag, Digest: digest, MediaType: mediaType})
+			continue
+		}
+		fmt.Printf("%s:%s@%s\n", ref.Name(), tag, digest)
+	}
+	if out != nil {
+		return writeRecords(out, records...)
+	}
+	return nil
+}